@@ -0,0 +1,104 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// LoadCurrency mengembalikan kode mata uang toko dari env CURRENCY. Nilainya dipasang ke
+// models.DefaultCurrency oleh main sebelum database dibuka; mengubahnya tidak mengubah
+// arti data lama karena produk dan order dalam mata uang lain ditolak saat dibaca.
+func LoadCurrency() string {
+	return strings.ToUpper(strings.TrimSpace(getEnv("CURRENCY", "IDR")))
+}
+
+// OrderConfig berisi pengaturan business rule order yang bisa diubah lewat env
+type OrderConfig struct {
+	// CancelCutoff adalah status terakhir di mana customer masih boleh membatalkan order
+	CancelCutoff string
+	// IdempotencyKeyTTL adalah lama penyimpanan hasil request dengan header Idempotency-Key
+	IdempotencyKeyTTL time.Duration
+	// IdempotencyKeyLease adalah lama key ditahan request yang sedang diproses sebelum
+	// boleh diambil alih retry, misal jika proses mati sebelum menyimpan response
+	IdempotencyKeyLease time.Duration
+	// TaxRate adalah tarif pajak dalam bentuk pecahan, misal 0.11 untuk 11%
+	TaxRate float64
+	// ShippingFee adalah ongkos kirim flat per order dalam bentuk desimal, misal "15000.00"
+	ShippingFee string
+	// AllocationStrategy adalah nama strategi pemilihan gudang: "single_first" atau "priority"
+	AllocationStrategy string
+	// ReservationTTL adalah lama stok ditahan untuk checkout sebelum dilepas otomatis
+	ReservationTTL time.Duration
+	// ReservationSweepInterval adalah jeda antar pengecekan reservasi yang kedaluwarsa
+	ReservationSweepInterval time.Duration
+}
+
+func LoadOrderConfig() OrderConfig {
+	return OrderConfig{
+		CancelCutoff:             getEnv("ORDER_CANCEL_CUTOFF", "paid"),
+		IdempotencyKeyTTL:        getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		IdempotencyKeyLease:      getEnvDuration("IDEMPOTENCY_KEY_LEASE", time.Minute),
+		TaxRate:                  getEnvFloat("ORDER_TAX_RATE", 0),
+		ShippingFee:              getEnv("ORDER_SHIPPING_FEE", "0"),
+		AllocationStrategy:       getEnv("ORDER_ALLOCATION_STRATEGY", "single_first"),
+		ReservationTTL:           getEnvDuration("RESERVATION_TTL", 15*time.Minute),
+		ReservationSweepInterval: getEnvDuration("RESERVATION_SWEEP_INTERVAL", time.Minute),
+	}
+}
+
+// ImageConfig berisi pengaturan penyimpanan gambar produk
+type ImageConfig struct {
+	// Storage adalah backend penyimpanan gambar; saat ini hanya "local"
+	Storage string
+	// LocalDir adalah direktori penyimpanan untuk storage local
+	LocalDir string
+	// PublicURL adalah prefix URL gambar yang dikirim ke client
+	PublicURL string
+	// MaxSize adalah ukuran maksimum satu file gambar dalam byte
+	MaxSize int64
+}
+
+func LoadImageConfig() ImageConfig {
+	return ImageConfig{
+		Storage:   getEnv("IMAGE_STORAGE", "local"),
+		LocalDir:  getEnv("IMAGE_LOCAL_DIR", "uploads/images"),
+		PublicURL: getEnv("IMAGE_PUBLIC_URL", "/images"),
+		MaxSize:   getEnvInt64("IMAGE_MAX_SIZE", 5<<20),
+	}
+}
+
+// SearchConfig berisi pengaturan pencarian produk
+type SearchConfig struct {
+	// Backend adalah index pencarian: "mysql" (index FULLTEXT) atau "memory" (index in-process)
+	Backend string
+	// RefreshInterval adalah jeda pemuatan ulang index memory dari database
+	RefreshInterval time.Duration
+}
+
+func LoadSearchConfig() SearchConfig {
+	return SearchConfig{
+		Backend:         getEnv("SEARCH_BACKEND", "mysql"),
+		RefreshInterval: getEnvDuration("SEARCH_REFRESH_INTERVAL", time.Minute),
+	}
+}
+
+// AlertConfig berisi pengaturan pengiriman alert low-stock
+type AlertConfig struct {
+	// Notifier adalah tujuan alert: "log", "webhook" atau "email"
+	Notifier string
+	// WebhookURL dipakai oleh notifier webhook
+	WebhookURL string
+	// EmailTo adalah penerima email alert untuk notifier email
+	EmailTo string
+	// DispatchInterval adalah jeda pengiriman event low-stock yang tertunda
+	DispatchInterval time.Duration
+}
+
+func LoadAlertConfig() AlertConfig {
+	return AlertConfig{
+		Notifier:         getEnv("LOW_STOCK_NOTIFIER", "log"),
+		WebhookURL:       getEnv("LOW_STOCK_WEBHOOK_URL", ""),
+		EmailTo:          getEnv("LOW_STOCK_EMAIL_TO", ""),
+		DispatchInterval: getEnvDuration("LOW_STOCK_DISPATCH_INTERVAL", 30*time.Second),
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+)
+
+// moneyColumns adalah kolom nominal yang dulu bertipe float dan kini disimpan
+// sebagai integer minor unit (models.Money)
+var moneyColumns = map[string][]string{
+	"products":    {"price"},
+	"order_items": {"price", "line_total"},
+	"orders":      {"subtotal", "discount", "tax", "shipping_fee", "grand_total"},
+}
+
+// Migrate menjalankan auto migrate semua model beserta pengisian data untuk kolom baru
+func Migrate(db *gorm.DB) error {
+	if err := migrateMoneyColumns(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Category{},
+		&models.Tag{},
+		&models.Product{},
+		&models.ProductVariant{},
+		&models.ProductImage{},
+		&models.Review{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.OrderStatusHistory{},
+		&models.CartItem{},
+		&models.IdempotencyKey{},
+		&models.InventoryMovement{},
+		&models.Warehouse{},
+		&models.StockLevel{},
+		&models.StockReservation{},
+		&models.LowStockEvent{},
+		&models.EmailOutbox{},
+	); err != nil {
+		return err
+	}
+	if err := backfillCurrency(db); err != nil {
+		return err
+	}
+	if err := backfillOrderTotals(db); err != nil {
+		return err
+	}
+	if err := backfillOrderItemSnapshots(db); err != nil {
+		return err
+	}
+	if err := backfillInventoryOpening(db); err != nil {
+		return err
+	}
+	if err := backfillWarehouseStock(db); err != nil {
+		return err
+	}
+	return migrateSearchIndexes(db)
+}
+
+// searchIndexes adalah index FULLTEXT untuk pencarian produk, per tabel
+var searchIndexes = map[string]struct{ name, columns string }{
+	"products": {"idx_products_search", "name, sku"},
+	"tags":     {"idx_tags_search", "name"},
+}
+
+// migrateSearchIndexes membuat index FULLTEXT yang dipakai MySQLSearchIndex. Index ini
+// khusus MySQL sehingga tidak ditulis di tag model; dialect lain dilewati.
+func migrateSearchIndexes(db *gorm.DB) error {
+	if db.Dialector.Name() != "mysql" {
+		return nil
+	}
+	migrator := db.Migrator()
+	for table, index := range searchIndexes {
+		if migrator.HasIndex(table, index.name) {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX `%s` ON `%s` (%s)", index.name, table, index.columns)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultWarehouseCode adalah gudang yang dibuat otomatis untuk menampung stok lama
+const defaultWarehouseCode = "MAIN"
+
+// backfillWarehouseStock memastikan ada minimal satu gudang, lalu memindahkan stok dan
+// movement lama yang belum punya gudang ke gudang tersebut. Aman dijalankan ulang.
+func backfillWarehouseStock(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Warehouse{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			fallback := models.Warehouse{Code: defaultWarehouseCode, Name: "Main Warehouse", Active: true}
+			if err := tx.Create(&fallback).Error; err != nil {
+				return err
+			}
+		}
+		var warehouse models.Warehouse
+		if err := tx.Order("priority").Order("id").First(&warehouse).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE inventory_movements SET warehouse_id = ? WHERE warehouse_id = 0", warehouse.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO stock_levels (warehouse_id, product_id, variant_id, quantity)
+			SELECT ?, v.product_id, v.id, v.stock FROM product_variants v
+			WHERE v.stock <> 0 AND NOT EXISTS (SELECT 1 FROM stock_levels l WHERE l.product_id = v.product_id)`,
+			warehouse.ID).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO stock_levels (warehouse_id, product_id, variant_id, quantity)
+			SELECT ?, p.id, 0, p.stock FROM products p
+			WHERE p.stock <> 0
+				AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id)
+				AND NOT EXISTS (SELECT 1 FROM stock_levels l WHERE l.product_id = p.id)`,
+			warehouse.ID).Error
+	})
+}
+
+// backfillInventoryOpening mencatat stok yang sudah ada sebelum ledger inventori dibuat
+// sebagai movement adjustment "opening balance", sehingga jumlah ledger langsung sama
+// dengan stok. Produk yang sudah punya movement dilewati agar aman dijalankan ulang.
+func backfillInventoryOpening(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO inventory_movements (product_id, variant_id, delta, reason, note, created_at)
+			SELECT v.product_id, v.id, v.stock, ?, 'opening balance', UNIX_TIMESTAMP()
+			FROM product_variants v
+			WHERE v.stock <> 0 AND NOT EXISTS (SELECT 1 FROM inventory_movements m WHERE m.product_id = v.product_id)`,
+			models.MovementAdjustment).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO inventory_movements (product_id, delta, reason, note, created_at)
+			SELECT p.id, p.stock, ?, 'opening balance', UNIX_TIMESTAMP()
+			FROM products p
+			WHERE p.stock <> 0
+				AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id)
+				AND NOT EXISTS (SELECT 1 FROM inventory_movements m WHERE m.product_id = p.id)`,
+			models.MovementAdjustment).Error
+	})
+}
+
+// backfillOrderItemSnapshots mengisi snapshot produk untuk item order lama.
+// Nama/SKU saat order dibuat tidak tersimpan, sehingga diisi dari data produk
+// sekarang (termasuk produk arsip) sebagai perkiraan terbaik.
+func backfillOrderItemSnapshots(db *gorm.DB) error {
+	return db.Exec(`UPDATE order_items SET
+		product_name = COALESCE((SELECT name FROM products WHERE products.id = order_items.product_id), ''),
+		product_sku = COALESCE((SELECT sku FROM products WHERE products.id = order_items.product_id), '')
+		WHERE product_name = ''`).Error
+}
+
+// backfillCurrency mencatat mata uang produk dan order yang dibuat sebelum kolom currency
+// ada. Nominalnya disimpan dalam mata uang toko saat itu, yaitu DefaultCurrency.
+func backfillCurrency(db *gorm.DB) error {
+	for _, table := range []string{"products", "orders"} {
+		if err := db.Exec("UPDATE "+table+" SET currency = ? WHERE currency = ''", models.DefaultCurrency).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillOrderTotals mengisi total untuk order yang dibuat sebelum kolom total ada.
+// Pajak dan ongkir order lama tidak diketahui, sehingga grand total = subtotal.
+func backfillOrderTotals(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("UPDATE order_items SET line_total = price * quantity WHERE line_total = 0").Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE orders SET
+			subtotal = (SELECT COALESCE(SUM(line_total), 0) FROM order_items WHERE order_items.order_id = orders.id),
+			grand_total = subtotal
+			WHERE subtotal = 0 AND grand_total = 0`).Error
+	})
+}
+
+// migrateMoneyColumns mengubah kolom nominal float lama menjadi BIGINT minor unit.
+// Nilai dikonversi lewat DECIMAL agar pembulatan sen tidak terpengaruh galat float.
+// Konversi ditulis ke kolom sementara lalu ditukar dalam satu ALTER TABLE, sehingga
+// aman dijalankan ulang jika proses sempat terhenti.
+func migrateMoneyColumns(db *gorm.DB) error {
+	factor := int64(math.Pow10(models.MinorDigits(models.DefaultCurrency)))
+	migrator := db.Migrator()
+	for table, columns := range moneyColumns {
+		if !migrator.HasTable(table) {
+			continue
+		}
+		columnTypes, err := migrator.ColumnTypes(table)
+		if err != nil {
+			return err
+		}
+		types := make(map[string]string, len(columnTypes))
+		for _, ct := range columnTypes {
+			types[ct.Name()] = strings.ToLower(ct.DatabaseTypeName())
+		}
+		for _, column := range columns {
+			switch types[column] {
+			case "float", "double", "decimal", "real":
+			default:
+				continue
+			}
+			tmp := column + "_minor"
+			if _, ok := types[tmp]; !ok {
+				if err := db.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` BIGINT NOT NULL DEFAULT 0", table, tmp)).Error; err != nil {
+					return err
+				}
+			}
+			if err := db.Exec(fmt.Sprintf("UPDATE `%s` SET `%s` = ROUND(CAST(`%s` AS DECIMAL(30,6)) * %d)", table, tmp, column, factor)).Error; err != nil {
+				return err
+			}
+			if err := db.Exec(fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`, CHANGE COLUMN `%s` `%s` BIGINT NOT NULL DEFAULT 0", table, column, tmp, column)).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "github.com/wahyuutomoputra/order-management/models"
+
+// CartItemRequest adalah DTO untuk menambah produk ke keranjang
+
+type CartItemRequest struct {
+	ProductID uint `json:"product_id" validate:"required"`
+	VariantID uint `json:"variant_id"`
+	Quantity  int  `json:"quantity" validate:"required,gt=0"`
+}
+
+// UpdateCartItemRequest adalah DTO untuk mengubah quantity produk di keranjang
+
+type UpdateCartItemRequest struct {
+	Quantity int `json:"quantity" validate:"required,gt=0"`
+}
+
+// CartItemResponse adalah satu baris keranjang dengan harga dan stok terkini
+
+type CartItemResponse struct {
+	ProductID uint              `json:"product_id"`
+	VariantID uint              `json:"variant_id,omitempty"`
+	SKU       string            `json:"sku,omitempty"`
+	Options   models.Attributes `json:"options,omitempty"`
+	Name      string            `json:"name"`
+	Price     models.Money      `json:"price" swaggertype:"string" example:"12.34"`
+	Quantity  int               `json:"quantity"`
+	LineTotal models.Money      `json:"line_total" swaggertype:"string" example:"12.34"`
+	// Stock adalah stok yang bisa dibeli user, tidak termasuk reservasi customer lain
+	Stock     int  `json:"stock"`
+	Available bool `json:"available"`
+}
+
+// CartResponse adalah isi keranjang user
+
+type CartResponse struct {
+	Items     []CartItemResponse `json:"items"`
+	Total     models.Money       `json:"total" swaggertype:"string" example:"12.34"`
+	Available bool               `json:"available"`
+}
+
+// ReservationResponse adalah stok yang sedang ditahan untuk checkout customer.
+// ExpiresAt adalah unix detik saat reservasi dilepas otomatis.
+
+type ReservationResponse struct {
+	Reservations []models.StockReservation `json:"reservations"`
+	ExpiresAt    int64                     `json:"expires_at"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+// CategoryRequest adalah DTO untuk request pembuatan/ubah kategori.
+// Slug dibuat otomatis dari Name jika kosong.
+
+type CategoryRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Slug     string `json:"slug" validate:"omitempty,max=120"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// TagRequest adalah DTO untuk request pembuatan/ubah tag
+
+type TagRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50"`
+}
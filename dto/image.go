@@ -0,0 +1,7 @@
+package dto
+
+// ImageOrderRequest adalah urutan baru gambar produk; harus berisi semua gambar produk
+
+type ImageOrderRequest struct {
+	ImageIDs []uint `json:"image_ids" validate:"required,min=1,dive,gt=0"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+// StockMismatch adalah produk, varian atau stok gudang yang berbeda dengan jumlah ledger.
+// WarehouseID terisi jika selisih ada pada stok per gudang.
+
+type StockMismatch struct {
+	ProductID   uint  `json:"product_id"`
+	VariantID   *uint `json:"variant_id,omitempty"`
+	WarehouseID *uint `json:"warehouse_id,omitempty"`
+	Stock       int64 `json:"stock"`
+	LedgerStock int64 `json:"ledger_stock"`
+}
+
+// ReconciliationResponse adalah hasil pengecekan stok terhadap ledger inventori
+
+type ReconciliationResponse struct {
+	Consistent bool            `json:"consistent"`
+	Mismatches []StockMismatch `json:"mismatches"`
+}
+
+// ReconcileQuery adalah query parameter reconcile; ProductID kosong berarti semua produk
+
+type ReconcileQuery struct {
+	ProductID uint `form:"product_id"`
+}
+
+// WarehouseRequest adalah DTO untuk request pembuatan/ubah gudang.
+// Active nil saat create berarti gudang langsung aktif.
+
+type WarehouseRequest struct {
+	Code     string `json:"code" validate:"required,max=32"`
+	Name     string `json:"name" validate:"required,max=255"`
+	Priority int    `json:"priority" validate:"gte=0"`
+	Active   *bool  `json:"active"`
+}
@@ -5,6 +5,7 @@ package dto
 
 type OrderItemInput struct {
 	ProductID uint `json:"product_id" validate:"required"`
+	VariantID uint `json:"variant_id"` // wajib jika produk memiliki varian
 	Quantity  int  `json:"quantity" validate:"required,gt=0"`
 }
 
@@ -14,3 +15,31 @@ type OrderItemInput struct {
 type OrderRequest struct {
 	Items []OrderItemInput `json:"items" validate:"required,dive"`
 }
+
+// UpdateOrderStatusRequest adalah DTO untuk request perubahan status order oleh admin
+
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=pending paid packed shipped delivered cancelled refunded"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+// CancelOrderRequest adalah DTO untuk request pembatalan order
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// OrderFilter adalah query parameter untuk daftar order admin.
+// From/To menerima tanggal (2006-01-02) atau RFC3339, MinTotal/MaxTotal berupa desimal.
+
+type OrderFilter struct {
+	UserID    uint   `form:"user_id"`
+	Status    string `form:"status" validate:"omitempty,oneof=pending paid packed shipped delivered cancelled refunded"`
+	From      string `form:"from"`
+	To        string `form:"to"`
+	ProductID uint   `form:"product_id"`
+	MinTotal  string `form:"min_total"`
+	MaxTotal  string `form:"max_total"`
+	Sort      string `form:"sort" validate:"omitempty,oneof=created_at -created_at grand_total -grand_total id -id"`
+	PageQuery
+}
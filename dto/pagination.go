@@ -0,0 +1,47 @@
+package dto
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageQuery adalah query parameter pagination berbasis offset
+
+type PageQuery struct {
+	Page  int `form:"page" validate:"omitempty,gte=1"`
+	Limit int `form:"limit" validate:"omitempty,gte=1,lte=100"`
+}
+
+// Normalize mengisi nilai default page dan limit
+func (q *PageQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 {
+		q.Limit = DefaultPageLimit
+	}
+	if q.Limit > MaxPageLimit {
+		q.Limit = MaxPageLimit
+	}
+}
+
+func (q PageQuery) Offset() int {
+	return (q.Page - 1) * q.Limit
+}
+
+// PageMeta adalah metadata pagination yang dikirim bersama data
+
+type PageMeta struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+func NewPageMeta(q PageQuery, total int64) PageMeta {
+	totalPages := total / int64(q.Limit)
+	if total%int64(q.Limit) != 0 {
+		totalPages++
+	}
+	return PageMeta{Page: q.Page, Limit: q.Limit, Total: total, TotalPages: totalPages}
+}
@@ -1,9 +1,94 @@
 package dto
 
+import "github.com/wahyuutomoputra/order-management/models"
+
 // ProductRequest adalah DTO untuk request pembuatan/ubah produk
 
 type ProductRequest struct {
-	Name  string  `json:"name" validate:"required,min=2"`
-	Price float64 `json:"price" validate:"required,gt=0"`
-	Stock int     `json:"stock" validate:"required,gte=0"`
+	Name  string       `json:"name" validate:"required,min=2,max=255"`
+	Price models.Money `json:"price" validate:"required,gt=0" swaggertype:"string" example:"12.34"`
+	// Stock opsional: saat create nil berarti 0, saat update nil berarti stok tidak diubah.
+	// Saat update Stock adalah total stok di semua gudang dan ditolak jika stok produk
+	// tersebar di beberapa gudang. Untuk penyesuaian relatif per gudang gunakan
+	// POST /admin/products/:id/stock.
+	Stock *int `json:"stock" validate:"omitempty,gte=0"`
+	// ReorderThreshold opsional: nil berarti tidak diubah, 0 mematikan alert low-stock
+	ReorderThreshold *int `json:"reorder_threshold" validate:"omitempty,gte=0"`
+	// SKU opsional: nil berarti tidak diubah, "" berarti dihapus
+	SKU *string `json:"sku" validate:"omitempty,max=64"`
+	// Version adalah versi produk yang sedang diubah, wajib saat update jika header
+	// If-Match tidak dikirim
+	Version *uint `json:"version" validate:"omitnil,gte=1"`
+	// CategoryIDs dan Tags bersifat opsional: nil berarti tidak diubah, [] berarti dikosongkan
+	CategoryIDs []uint   `json:"category_ids"`
+	Tags        []string `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+}
+
+// ProductPatchRequest adalah DTO untuk PATCH produk dengan semantik JSON Merge Patch
+// (RFC 7396): field yang tidak dikirim tidak diubah dan hanya field yang dikirim yang
+// divalidasi. null menghapus sku, category_ids dan tags; field wajib tidak boleh null.
+
+type ProductPatchRequest struct {
+	Name             *string       `json:"name" validate:"omitnil,min=2,max=255"`
+	Price            *models.Money `json:"price" validate:"omitnil,gt=0" swaggertype:"string" example:"12.34"`
+	Stock            *int          `json:"stock" validate:"omitnil,gte=0"`
+	ReorderThreshold *int          `json:"reorder_threshold" validate:"omitnil,gte=0"`
+	SKU              *string       `json:"sku" validate:"omitnil,max=64"`
+	// Version opsional; jika tidak dikirim (dan tanpa If-Match) dipakai versi saat produk dibaca
+	Version     *uint    `json:"version" validate:"omitnil,gte=1"`
+	CategoryIDs []uint   `json:"category_ids"`
+	Tags        []string `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+}
+
+// ProductFilter adalah query parameter untuk daftar produk.
+// MinPrice/MaxPrice berupa desimal, Q mencari substring nama produk.
+
+type ProductFilter struct {
+	Q        string `form:"q" validate:"max=100"`
+	MinPrice string `form:"min_price"`
+	MaxPrice string `form:"max_price"`
+	InStock  bool   `form:"in_stock"`
+	// CategoryID menampilkan produk di kategori tersebut beserta seluruh sub-kategorinya
+	CategoryID uint   `form:"category_id"`
+	Tag        string `form:"tag"`
+	Sort       string `form:"sort" validate:"omitempty,oneof=name -name price -price stock -stock id -id"`
+	PageQuery
+}
+
+// ProductSearchQuery adalah query parameter pencarian full-text produk
+
+type ProductSearchQuery struct {
+	Q string `form:"q" validate:"required,max=100"`
+	PageQuery
+}
+
+// ProductSearchHit adalah satu hasil pencarian produk. Highlight berisi nama produk
+// (HTML-escaped) dengan kata yang cocok dibungkus <mark>.
+
+type ProductSearchHit struct {
+	Product   models.Product `json:"product"`
+	Score     float64        `json:"score"`
+	Highlight string         `json:"highlight"`
+}
+
+// VariantRequest adalah DTO untuk request pembuatan/ubah varian produk.
+// Price kosong berarti varian mengikuti harga produk.
+
+type VariantRequest struct {
+	SKU     string            `json:"sku" validate:"required,max=64"`
+	Options map[string]string `json:"options" validate:"omitempty,max=10,dive,keys,min=1,max=50,endkeys,min=1,max=100"`
+	Price   *models.Money     `json:"price" validate:"omitempty,gt=0" swaggertype:"string" example:"12.34"`
+	// Stock opsional: saat create nil berarti 0, saat update nil berarti stok tidak diubah
+	Stock *int `json:"stock" validate:"omitempty,gte=0"`
+}
+
+// StockAdjustmentRequest adalah DTO untuk penyesuaian stok relatif.
+// VariantID wajib untuk produk yang memiliki varian, WarehouseID kosong berarti gudang default.
+
+type StockAdjustmentRequest struct {
+	Delta       int    `json:"delta" validate:"required,ne=0"`
+	Reason      string `json:"reason" validate:"required,oneof=restock adjustment"`
+	VariantID   uint   `json:"variant_id"`
+	WarehouseID uint   `json:"warehouse_id"`
+	Note        string `json:"note" validate:"max=255"`
 }
@@ -0,0 +1,51 @@
+package dto
+
+// ProductImportRow adalah satu baris file import produk, baik kolom CSV maupun satu
+// objek JSONL. Produk dicocokkan berdasarkan SKU: SKU baru dibuat, SKU yang ada diubah.
+// Field kosong berarti tidak diubah; name dan price wajib untuk produk baru.
+// Di CSV, category_ids dan tags dipisahkan dengan "|".
+
+type ProductImportRow struct {
+	SKU              string   `json:"sku"`
+	Name             string   `json:"name,omitempty"`
+	Price            string   `json:"price,omitempty" example:"12.34"`
+	Stock            *int     `json:"stock,omitempty"`
+	ReorderThreshold *int     `json:"reorder_threshold,omitempty"`
+	CategoryIDs      []uint   `json:"category_ids,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+// ProductImportQuery adalah query parameter import produk. Format kosong ditentukan
+// dari ekstensi file.
+
+type ProductImportQuery struct {
+	Format string `form:"format" validate:"omitempty,oneof=csv jsonl"`
+	DryRun bool   `form:"dry_run"`
+}
+
+// ProductExportQuery adalah query parameter ekspor katalog
+
+type ProductExportQuery struct {
+	Format string `form:"format" validate:"omitempty,oneof=csv jsonl"`
+}
+
+// ImportRowError adalah error pada satu baris file import; Row dihitung dari baris
+// pertama file (header CSV adalah baris 1)
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	SKU   string `json:"sku,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportReport adalah hasil import produk. Perubahan hanya disimpan (Committed) jika
+// bukan dry run dan tidak ada baris yang error.
+
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Committed bool             `json:"committed"`
+	Total     int              `json:"total"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Errors    []ImportRowError `json:"errors"`
+}
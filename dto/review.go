@@ -0,0 +1,22 @@
+package dto
+
+// ReviewRequest adalah DTO untuk membuat review produk
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Body   string `json:"body" validate:"max=2000"`
+}
+
+// ReviewStatusRequest adalah DTO moderasi review oleh admin
+
+type ReviewStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=published hidden"`
+}
+
+// ReviewFilter adalah query parameter daftar review untuk admin
+
+type ReviewFilter struct {
+	ProductID uint   `form:"product_id"`
+	Status    string `form:"status" validate:"omitempty,oneof=published hidden"`
+	PageQuery
+}
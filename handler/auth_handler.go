@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 
@@ -15,7 +14,7 @@ import (
 	"github.com/wahyuutomoputra/order-management/utils"
 )
 
-var validate = validator.New()
+var validate = utils.NewValidator()
 
 type AuthHandler struct {
 	UserService *service.UserService
@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/service"
+	"github.com/wahyuutomoputra/order-management/utils"
+)
+
+type CartHandler struct {
+	CartService *service.CartService
+}
+
+func NewCartHandler(cartService *service.CartService) *CartHandler {
+	return &CartHandler{CartService: cartService}
+}
+
+// GetCartHandler godoc
+// @Summary Get cart
+// @Description Get current user's cart with live prices and stock availability
+// @Tags Cart
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse{data=dto.CartResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart [get]
+func (h *CartHandler) GetCartHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		cart, err := h.CartService.GetCart(userID.(uint))
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to get cart")
+			return
+		}
+		utils.JSONSuccess(c, cart, "Cart")
+	}
+}
+
+// AddCartItemHandler godoc
+// @Summary Add item to cart
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param data body dto.CartItemRequest true "Cart item data"
+// @Success 200 {object} utils.SuccessResponse{data=dto.CartResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart/items [post]
+func (h *CartHandler) AddCartItemHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CartItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		userID, _ := c.Get("userID")
+		if err := h.CartService.AddItem(userID.(uint), req); err != nil {
+			switch {
+			case errors.Is(err, service.ErrProductNotFound):
+				utils.JSONError(c, 404, "Product not found")
+				return
+			case errors.Is(err, service.ErrVariantNotFound):
+				utils.JSONError(c, 404, "Product variant not found")
+				return
+			case errors.Is(err, service.ErrVariantRequired):
+				utils.JSONError(c, 400, err.Error())
+				return
+			}
+			utils.JSONError(c, 500, "Failed to add item to cart")
+			return
+		}
+		h.respondCart(c, userID.(uint), "Item added to cart")
+	}
+}
+
+// UpdateCartItemHandler godoc
+// @Summary Update cart item quantity
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param product_id path int true "Product ID"
+// @Param variant_id query int false "Variant ID"
+// @Param data body dto.UpdateCartItemRequest true "Quantity data"
+// @Success 200 {object} utils.SuccessResponse{data=dto.CartResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart/items/{product_id} [put]
+func (h *CartHandler) UpdateCartItemHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var productID, variantID uint
+		if err := parseUintParam(c, "product_id", &productID); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := parseUintQuery(c, "variant_id", &variantID); err != nil {
+			utils.JSONError(c, 400, "Invalid variant id")
+			return
+		}
+		var req dto.UpdateCartItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		userID, _ := c.Get("userID")
+		if err := h.CartService.UpdateItem(userID.(uint), productID, variantID, req.Quantity); err != nil {
+			if errors.Is(err, service.ErrCartItemNotFound) {
+				utils.JSONError(c, 404, "Cart item not found")
+				return
+			}
+			utils.JSONError(c, 500, "Failed to update cart item")
+			return
+		}
+		h.respondCart(c, userID.(uint), "Cart item updated")
+	}
+}
+
+// RemoveCartItemHandler godoc
+// @Summary Remove item from cart
+// @Tags Cart
+// @Produce json
+// @Param product_id path int true "Product ID"
+// @Param variant_id query int false "Variant ID"
+// @Success 200 {object} utils.SuccessResponse{data=dto.CartResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart/items/{product_id} [delete]
+func (h *CartHandler) RemoveCartItemHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var productID, variantID uint
+		if err := parseUintParam(c, "product_id", &productID); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := parseUintQuery(c, "variant_id", &variantID); err != nil {
+			utils.JSONError(c, 400, "Invalid variant id")
+			return
+		}
+		userID, _ := c.Get("userID")
+		if err := h.CartService.RemoveItem(userID.(uint), productID, variantID); err != nil {
+			if errors.Is(err, service.ErrCartItemNotFound) {
+				utils.JSONError(c, 404, "Cart item not found")
+				return
+			}
+			utils.JSONError(c, 500, "Failed to remove cart item")
+			return
+		}
+		h.respondCart(c, userID.(uint), "Cart item removed")
+	}
+}
+
+// ClearCartHandler godoc
+// @Summary Clear cart
+// @Tags Cart
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart [delete]
+func (h *CartHandler) ClearCartHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		if err := h.CartService.Clear(userID.(uint)); err != nil {
+			utils.JSONError(c, 500, "Failed to clear cart")
+			return
+		}
+		utils.JSONSuccess(c, nil, "Cart cleared")
+	}
+}
+
+// ReserveCartHandler godoc
+// @Summary Reserve stock for checkout
+// @Description Hold stock for every cart item until expires_at. Reserved quantities are not available to other customers; calling again replaces the previous reservation.
+// @Tags Cart
+// @Produce json
+// @Success 201 {object} utils.SuccessResponse{data=dto.ReservationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart/reservation [post]
+func (h *CartHandler) ReserveCartHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		reservation, err := h.CartService.Reserve(userID.(uint))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrCartEmpty), errors.Is(err, service.ErrVariantRequired):
+				utils.JSONError(c, 400, err.Error())
+			case errors.Is(err, service.ErrProductNotFound):
+				utils.JSONError(c, 404, "Product not found")
+			case errors.Is(err, service.ErrVariantNotFound):
+				utils.JSONError(c, 404, "Product variant not found")
+			case errors.Is(err, service.ErrInsufficientStock):
+				utils.JSONError(c, 409, err.Error())
+			default:
+				utils.JSONError(c, 500, "Failed to reserve stock")
+			}
+			return
+		}
+		utils.JSONCreated(c, reservation, "Stock reserved")
+	}
+}
+
+// GetReservationHandler godoc
+// @Summary Get checkout reservation
+// @Tags Cart
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse{data=dto.ReservationResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart/reservation [get]
+func (h *CartHandler) GetReservationHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		reservation, err := h.CartService.Reservation(userID.(uint))
+		if err != nil {
+			if errors.Is(err, service.ErrNoReservation) {
+				utils.JSONError(c, 404, err.Error())
+				return
+			}
+			utils.JSONError(c, 500, "Failed to get reservation")
+			return
+		}
+		utils.JSONSuccess(c, reservation, "Reservation")
+	}
+}
+
+// ReleaseReservationHandler godoc
+// @Summary Release checkout reservation
+// @Tags Cart
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart/reservation [delete]
+func (h *CartHandler) ReleaseReservationHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		if err := h.CartService.ReleaseReservation(userID.(uint)); err != nil {
+			utils.JSONError(c, 500, "Failed to release reservation")
+			return
+		}
+		utils.JSONSuccess(c, nil, "Reservation released")
+	}
+}
+
+// CheckoutCartHandler godoc
+// @Summary Checkout cart
+// @Description Convert the current cart into an order and empty the cart. Active reservations are converted into stock deductions.
+// @Tags Cart
+// @Produce json
+// @Success 201 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /cart/checkout [post]
+func (h *CartHandler) CheckoutCartHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		order, err := h.CartService.Checkout(userID.(uint))
+		if err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		utils.JSONCreated(c, order, "Order created")
+	}
+}
+
+func (h *CartHandler) respondCart(c *gin.Context, userID uint, message string) {
+	cart, err := h.CartService.GetCart(userID)
+	if err != nil {
+		utils.JSONError(c, 500, "Failed to get cart")
+		return
+	}
+	utils.JSONSuccess(c, cart, message)
+}
@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/service"
+	"github.com/wahyuutomoputra/order-management/utils"
+)
+
+type CategoryHandler struct {
+	CategoryService *service.CategoryService
+	TagService      *service.TagService
+}
+
+func NewCategoryHandler(categoryService *service.CategoryService, tagService *service.TagService) *CategoryHandler {
+	return &CategoryHandler{CategoryService: categoryService, TagService: tagService}
+}
+
+// ListCategoriesHandler godoc
+// @Summary List categories as a tree
+// @Tags Category
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /categories [get]
+func (h *CategoryHandler) ListCategoriesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tree, err := h.CategoryService.Tree()
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to get categories")
+			return
+		}
+		utils.JSONSuccess(c, tree, "Category list")
+	}
+}
+
+// CreateCategoryHandler godoc
+// @Summary Create category
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param data body dto.CategoryRequest true "Category data"
+// @Success 201 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/categories [post]
+// @Security BearerAuth
+func (h *CategoryHandler) CreateCategoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CategoryRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		category, err := h.CategoryService.Create(req)
+		if err != nil {
+			categoryError(c, err, "Failed to create category")
+			return
+		}
+		utils.JSONCreated(c, category, "Category created")
+	}
+}
+
+// UpdateCategoryHandler godoc
+// @Summary Update category
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param data body dto.CategoryRequest true "Category data"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/categories/{id} [put]
+// @Security BearerAuth
+func (h *CategoryHandler) UpdateCategoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid category id")
+			return
+		}
+		var req dto.CategoryRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		category, err := h.CategoryService.Update(id, req)
+		if err != nil {
+			categoryError(c, err, "Failed to update category")
+			return
+		}
+		utils.JSONSuccess(c, category, "Category updated")
+	}
+}
+
+// DeleteCategoryHandler godoc
+// @Summary Delete category
+// @Tags Category
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/categories/{id} [delete]
+// @Security BearerAuth
+func (h *CategoryHandler) DeleteCategoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid category id")
+			return
+		}
+		if err := h.CategoryService.Delete(id); err != nil {
+			categoryError(c, err, "Failed to delete category")
+			return
+		}
+		utils.JSONSuccess(c, nil, "Category deleted")
+	}
+}
+
+// ListTagsHandler godoc
+// @Summary List tags
+// @Tags Category
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /tags [get]
+func (h *CategoryHandler) ListTagsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tags, err := h.TagService.FindAll()
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to get tags")
+			return
+		}
+		utils.JSONSuccess(c, tags, "Tag list")
+	}
+}
+
+// CreateTagHandler godoc
+// @Summary Create tag
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param data body dto.TagRequest true "Tag data"
+// @Success 201 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/tags [post]
+// @Security BearerAuth
+func (h *CategoryHandler) CreateTagHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		tag, err := h.TagService.Create(req.Name)
+		if err != nil {
+			tagError(c, err, "Failed to create tag")
+			return
+		}
+		utils.JSONCreated(c, tag, "Tag created")
+	}
+}
+
+// UpdateTagHandler godoc
+// @Summary Rename tag
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param data body dto.TagRequest true "Tag data"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/tags/{id} [put]
+// @Security BearerAuth
+func (h *CategoryHandler) UpdateTagHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid tag id")
+			return
+		}
+		var req dto.TagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		tag, err := h.TagService.Update(id, req.Name)
+		if err != nil {
+			tagError(c, err, "Failed to update tag")
+			return
+		}
+		utils.JSONSuccess(c, tag, "Tag updated")
+	}
+}
+
+// DeleteTagHandler godoc
+// @Summary Delete tag
+// @Tags Category
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/tags/{id} [delete]
+// @Security BearerAuth
+func (h *CategoryHandler) DeleteTagHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid tag id")
+			return
+		}
+		if err := h.TagService.Delete(id); err != nil {
+			tagError(c, err, "Failed to delete tag")
+			return
+		}
+		utils.JSONSuccess(c, nil, "Tag deleted")
+	}
+}
+
+func categoryError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		utils.JSONError(c, 404, err.Error())
+	case errors.Is(err, service.ErrCategorySlugTaken), errors.Is(err, service.ErrCategoryHasChildren):
+		utils.JSONError(c, 409, err.Error())
+	case errors.Is(err, service.ErrCategoryCycle), errors.Is(err, service.ErrCategorySlugInvalid):
+		utils.JSONError(c, 400, err.Error())
+	default:
+		utils.JSONError(c, 500, fallback)
+	}
+}
+
+func tagError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTagNotFound):
+		utils.JSONError(c, 404, err.Error())
+	case errors.Is(err, service.ErrTagExists):
+		utils.JSONError(c, 409, err.Error())
+	default:
+		utils.JSONError(c, 500, fallback)
+	}
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/service"
+	"github.com/wahyuutomoputra/order-management/utils"
+)
+
+// imageCacheControl dipakai untuk file gambar; key gambar unik per upload sehingga isinya tidak pernah berubah
+const imageCacheControl = "public, max-age=31536000, immutable"
+
+// multipartOverhead adalah ruang tambahan untuk boundary dan field lain di request upload
+const multipartOverhead = 1 << 20
+
+type ImageHandler struct {
+	ImageService *service.ImageService
+}
+
+func NewImageHandler(imageService *service.ImageService) *ImageHandler {
+	return &ImageHandler{ImageService: imageService}
+}
+
+// UploadImageHandler godoc
+// @Summary Upload product image
+// @Description Upload a JPEG, PNG, GIF or WebP image (type is detected from the file content). The image is appended after the existing images; the first image of a product becomes its primary image.
+// @Tags Product Image
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param image formData file true "Image file"
+// @Param primary formData bool false "Make this the primary image"
+// @Success 201 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 413 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/images [post]
+// @Security BearerAuth
+func (h *ImageHandler) UploadImageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.ImageService.MaxSize()+multipartOverhead)
+		header, err := c.FormFile("image")
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				utils.JSONError(c, http.StatusRequestEntityTooLarge, service.ErrImageTooLarge.Error())
+				return
+			}
+			utils.JSONError(c, 400, "Image file is required")
+			return
+		}
+		if header.Size > h.ImageService.MaxSize() {
+			utils.JSONError(c, http.StatusRequestEntityTooLarge, service.ErrImageTooLarge.Error())
+			return
+		}
+		primary, _ := strconv.ParseBool(c.PostForm("primary"))
+		file, err := header.Open()
+		if err != nil {
+			utils.JSONError(c, 400, "Failed to read image")
+			return
+		}
+		defer file.Close()
+		image, err := h.ImageService.Upload(c.Request.Context(), id, file, primary)
+		if err != nil {
+			imageError(c, err, "Failed to upload image")
+			return
+		}
+		utils.JSONCreated(c, image, "Image uploaded")
+	}
+}
+
+// ReorderImagesHandler godoc
+// @Summary Reorder product images
+// @Tags Product Image
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param data body dto.ImageOrderRequest true "All image ids of the product in the new order"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/images/order [put]
+// @Security BearerAuth
+func (h *ImageHandler) ReorderImagesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ImageOrderRequest
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := productValidate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		images, err := h.ImageService.Reorder(id, req.ImageIDs)
+		if err != nil {
+			imageError(c, err, "Failed to reorder images")
+			return
+		}
+		utils.JSONSuccess(c, images, "Images reordered")
+	}
+}
+
+// SetPrimaryImageHandler godoc
+// @Summary Set primary product image
+// @Tags Product Image
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param image_id path int true "Image ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/images/{image_id}/primary [put]
+// @Security BearerAuth
+func (h *ImageHandler) SetPrimaryImageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id, imageID uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := parseUintParam(c, "image_id", &imageID); err != nil {
+			utils.JSONError(c, 400, "Invalid image id")
+			return
+		}
+		image, err := h.ImageService.SetPrimary(id, imageID)
+		if err != nil {
+			imageError(c, err, "Failed to set primary image")
+			return
+		}
+		utils.JSONSuccess(c, image, "Primary image updated")
+	}
+}
+
+// DeleteImageHandler godoc
+// @Summary Delete product image
+// @Tags Product Image
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param image_id path int true "Image ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/images/{image_id} [delete]
+// @Security BearerAuth
+func (h *ImageHandler) DeleteImageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id, imageID uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := parseUintParam(c, "image_id", &imageID); err != nil {
+			utils.JSONError(c, 400, "Invalid image id")
+			return
+		}
+		if err := h.ImageService.Delete(c.Request.Context(), id, imageID); err != nil {
+			imageError(c, err, "Failed to delete image")
+			return
+		}
+		utils.JSONSuccess(c, nil, "Image deleted")
+	}
+}
+
+// ServeImageHandler godoc
+// @Summary Get image file
+// @Description Serve a stored image with long-lived cache headers. Supports If-Modified-Since and Range requests.
+// @Tags Product Image
+// @Produce image/jpeg,image/png,image/gif,image/webp
+// @Param key path string true "Image key"
+// @Success 200 {file} file
+// @Success 304 "Not modified"
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /images/{key} [get]
+func (h *ImageHandler) ServeImageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.Param("key"), "/")
+		file, modTime, err := h.ImageService.Open(c.Request.Context(), key)
+		if err != nil {
+			if errors.Is(err, service.ErrFileNotFound) {
+				utils.JSONError(c, 404, "Image not found")
+				return
+			}
+			utils.JSONError(c, 500, "Failed to read image")
+			return
+		}
+		defer file.Close()
+		c.Header("Cache-Control", imageCacheControl)
+		c.Header("X-Content-Type-Options", "nosniff")
+		http.ServeContent(c.Writer, c.Request, key, modTime, file)
+	}
+}
+
+// imageError memetakan error dari ImageService ke response HTTP
+func imageError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrProductNotFound):
+		utils.JSONError(c, 404, "Product not found")
+	case errors.Is(err, service.ErrImageNotFound):
+		utils.JSONError(c, 404, err.Error())
+	case errors.Is(err, service.ErrImageTooLarge):
+		utils.JSONError(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, service.ErrUnsupportedImageType), errors.Is(err, service.ErrInvalidImageOrder):
+		utils.JSONError(c, 400, err.Error())
+	case errors.Is(err, service.ErrTooManyImages):
+		utils.JSONError(c, 409, err.Error())
+	default:
+		utils.JSONError(c, 500, fallback)
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/service"
+	"github.com/wahyuutomoputra/order-management/utils"
+)
+
+type InventoryHandler struct {
+	InventoryService *service.InventoryService
+}
+
+func NewInventoryHandler(inventoryService *service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{InventoryService: inventoryService}
+}
+
+// ProductMovementsHandler godoc
+// @Summary List stock movements of a product
+// @Description Append-only inventory ledger entries of a product, newest first
+// @Tags Inventory
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.SuccessResponse{meta=dto.PageMeta}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/movements [get]
+// @Security BearerAuth
+func (h *InventoryHandler) ProductMovementsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		var page dto.PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(page); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		movements, meta, err := h.InventoryService.Movements(id, page)
+		if err != nil {
+			if errors.Is(err, service.ErrProductNotFound) {
+				utils.JSONError(c, 404, "Product not found")
+				return
+			}
+			utils.JSONError(c, 500, "Failed to get stock movements")
+			return
+		}
+		utils.JSONSuccessWithMeta(c, movements, meta, "Stock movements")
+	}
+}
+
+// LowStockHandler godoc
+// @Summary List low-stock products
+// @Description Products whose stock is at or below their reorder threshold, most critical first
+// @Tags Inventory
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.SuccessResponse{meta=dto.PageMeta}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/inventory/low-stock [get]
+// @Security BearerAuth
+func (h *InventoryHandler) LowStockHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page dto.PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(page); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		products, meta, err := h.InventoryService.LowStock(page)
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to get low-stock products")
+			return
+		}
+		utils.JSONSuccessWithMeta(c, products, meta, "Low-stock products")
+	}
+}
+
+// ReconcileInventoryHandler godoc
+// @Summary Reconcile stock with inventory ledger
+// @Description Lists products and variants whose stock differs from the sum of their ledger entries
+// @Tags Inventory
+// @Produce json
+// @Param product_id query int false "Only check this product"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ReconciliationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/inventory/reconcile [get]
+// @Security BearerAuth
+func (h *InventoryHandler) ReconcileInventoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query dto.ReconcileQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		result, err := h.InventoryService.Reconcile(query.ProductID)
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to reconcile inventory")
+			return
+		}
+		utils.JSONSuccess(c, result, "Inventory reconciliation")
+	}
+}
+
+// AdjustStockHandler godoc
+// @Summary Adjust product stock
+// @Description Relative stock adjustment (positive or negative delta) applied atomically to one warehouse and recorded in the inventory ledger. variant_id is required for products with variants; warehouse_id defaults to the highest-priority active warehouse.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param data body dto.StockAdjustmentRequest true "Stock adjustment"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/stock [post]
+// @Security BearerAuth
+func (h *InventoryHandler) AdjustStockHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		var req dto.StockAdjustmentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := productValidate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		userID, _ := c.Get("userID")
+		product, err := h.InventoryService.Adjust(id, req, userID.(uint))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrProductNotFound):
+				utils.JSONError(c, 404, "Product not found")
+			case errors.Is(err, service.ErrVariantNotFound):
+				utils.JSONError(c, 404, "Product variant not found")
+			case errors.Is(err, service.ErrWarehouseNotFound):
+				utils.JSONError(c, 404, err.Error())
+			case errors.Is(err, service.ErrVariantRequired):
+				utils.JSONError(c, 400, err.Error())
+			case errors.Is(err, service.ErrInsufficientStock), errors.Is(err, service.ErrNoActiveWarehouse):
+				utils.JSONError(c, 409, err.Error())
+			default:
+				utils.JSONError(c, 500, "Failed to adjust stock")
+			}
+			return
+		}
+		utils.JSONSuccess(c, product, "Stock adjusted")
+	}
+}
@@ -1,20 +1,28 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
 	"github.com/wahyuutomoputra/order-management/service"
 	"github.com/wahyuutomoputra/order-management/utils"
+	"gorm.io/gorm"
 )
 
 type OrderHandler struct {
-	OrderService *service.OrderService
+	OrderService       *service.OrderService
+	IdempotencyService *service.IdempotencyService
 }
 
-func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
-	return &OrderHandler{OrderService: orderService}
+func NewOrderHandler(orderService *service.OrderService, idempotencyService *service.IdempotencyService) *OrderHandler {
+	return &OrderHandler{OrderService: orderService, IdempotencyService: idempotencyService}
 }
 
 // CreateOrderHandler godoc
@@ -22,10 +30,14 @@ func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
 // @Tags Order
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
 // @Param data body dto.OrderRequest true "Order data"
 // @Success 201 {object} utils.SuccessResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
 // @Security BearerAuth
 // @Router /orders [post]
 func (h *OrderHandler) CreateOrderHandler() gin.HandlerFunc {
@@ -35,17 +47,93 @@ func (h *OrderHandler) CreateOrderHandler() gin.HandlerFunc {
 			utils.JSONError(c, 400, "Invalid request")
 			return
 		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
 		userID, _ := c.Get("userID")
-		order, err := h.OrderService.CreateOrder(userID.(uint), req.Items)
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			order, err := h.OrderService.CreateOrder(userID.(uint), req.Items)
+			if err != nil {
+				if isOrderRequestError(err) {
+					utils.JSONError(c, 400, err.Error())
+					return
+				}
+				utils.JSONError(c, 500, "Failed to create order")
+				return
+			}
+			order.CreatedAt = time.Now().Unix()
+			utils.JSONCreated(c, order, "Order created")
+			return
+		}
+		if len(key) > 255 {
+			utils.JSONError(c, 400, "Idempotency-Key is too long")
+			return
+		}
+
+		payload, _ := json.Marshal(req)
+		hash := sha256.Sum256(payload)
+		record, replay, err := h.IdempotencyService.Begin(userID.(uint), key, hex.EncodeToString(hash[:]))
 		if err != nil {
-			utils.JSONError(c, 400, err.Error())
+			switch {
+			case errors.Is(err, service.ErrIdempotencyInProgress):
+				utils.JSONError(c, 409, err.Error())
+			case errors.Is(err, service.ErrIdempotencyKeyReused):
+				utils.JSONError(c, 422, err.Error())
+			default:
+				utils.JSONError(c, 500, "Failed to process idempotency key")
+			}
+			return
+		}
+		if replay {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, "application/json; charset=utf-8", record.ResponseBody)
+			return
+		}
+
+		// response disimpan di transaksi yang sama dengan pembuatan order
+		_, err = h.OrderService.CreateOrderWith(userID.(uint), req.Items, func(tx *gorm.DB, order *models.Order) error {
+			order.CreatedAt = time.Now().Unix()
+			body, err := json.Marshal(utils.SuccessResponse{Success: true, Data: order, Message: "Order created"})
+			if err != nil {
+				return err
+			}
+			return h.IdempotencyService.Complete(tx, record, 201, body)
+		})
+		if err != nil && isOrderRequestError(err) {
+			// order ditolak karena isi request; response disimpan agar retry dengan key
+			// yang sama mendapat jawaban yang sama, bukan membuat order ketika stok kembali
+			body, _ := json.Marshal(utils.ErrorResponse{Success: false, Error: err.Error()})
+			err = h.IdempotencyService.Complete(nil, record, 400, body)
+		}
+		if err != nil {
+			if errors.Is(err, service.ErrIdempotencyInProgress) {
+				// key sudah diambil alih retry lain karena request ini melewati lease-nya
+				utils.JSONError(c, 409, err.Error())
+				return
+			}
+			// error server atau gangguan database: key dilepas agar client bisa mengulang.
+			// Jika gagal dilepas, key bisa diambil alih setelah lease-nya habis.
+			if releaseErr := h.IdempotencyService.Release(record); releaseErr != nil {
+				log.Printf("idempotency: failed to release key %d: %v", record.ID, releaseErr)
+			}
+			utils.JSONError(c, 500, "Failed to create order")
 			return
 		}
-		order.CreatedAt = time.Now().Unix()
-		utils.JSONCreated(c, order, "Order created")
+		c.Data(record.StatusCode, "application/json; charset=utf-8", record.ResponseBody)
 	}
 }
 
+// isOrderRequestError melaporkan apakah order gagal karena isi request (produk, varian
+// atau stok) sehingga dijawab 400; error lain adalah error server
+func isOrderRequestError(err error) bool {
+	return errors.Is(err, service.ErrProductNotFound) ||
+		errors.Is(err, service.ErrVariantNotFound) ||
+		errors.Is(err, service.ErrVariantRequired) ||
+		errors.Is(err, service.ErrInsufficientStock)
+}
+
 // OrderHistoryHandler godoc
 // @Summary Get order history
 // @Tags Order
@@ -66,3 +154,217 @@ func (h *OrderHandler) OrderHistoryHandler() gin.HandlerFunc {
 		utils.JSONSuccess(c, orders, "Order history")
 	}
 }
+
+// GetOrderHandler godoc
+// @Summary Get order detail
+// @Tags Order
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /orders/{id} [get]
+func (h *OrderHandler) GetOrderHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid order id")
+			return
+		}
+		userID, _ := c.Get("userID")
+		role, _ := c.Get("role")
+		order, err := h.OrderService.GetOrder(id, userID.(uint), role == "admin")
+		if err != nil {
+			if errors.Is(err, service.ErrOrderNotFound) {
+				utils.JSONError(c, 404, "Order not found")
+				return
+			}
+			utils.JSONError(c, 500, "Failed to get order")
+			return
+		}
+		utils.JSONSuccess(c, order, "Order detail")
+	}
+}
+
+// CancelOrderHandler godoc
+// @Summary Cancel order
+// @Description Cancel an order and restore the stock of its items
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body dto.CancelOrderRequest false "Cancel data"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Security BearerAuth
+// @Router /orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrderHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid order id")
+			return
+		}
+		var req dto.CancelOrderRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				utils.JSONError(c, 400, "Invalid request")
+				return
+			}
+			if err := validate.Struct(req); err != nil {
+				utils.JSONError(c, 400, err.Error())
+				return
+			}
+		}
+		userID, _ := c.Get("userID")
+		role, _ := c.Get("role")
+		order, err := h.OrderService.CancelOrder(id, userID.(uint), role == "admin", req.Reason)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrOrderNotFound):
+				utils.JSONError(c, 404, "Order not found")
+			case errors.Is(err, service.ErrOrderNotCancellable),
+				errors.Is(err, service.ErrOrderStockNotRestorable):
+				utils.JSONError(c, 409, err.Error())
+			default:
+				utils.JSONError(c, 500, "Failed to cancel order")
+			}
+			return
+		}
+		utils.JSONSuccess(c, order, "Order cancelled")
+	}
+}
+
+// ListOrdersHandler godoc
+// @Summary List orders (admin)
+// @Tags Order
+// @Produce json
+// @Param user_id query int false "Filter by user ID"
+// @Param status query string false "Filter by status" Enums(pending, paid, packed, shipped, delivered, cancelled, refunded)
+// @Param from query string false "Created at or after (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "Created at or before (YYYY-MM-DD or RFC3339)"
+// @Param product_id query int false "Only orders containing this product"
+// @Param min_total query string false "Minimum grand total"
+// @Param max_total query string false "Maximum grand total"
+// @Param sort query string false "Sort field, prefix with - for descending" Enums(created_at, -created_at, grand_total, -grand_total, id, -id)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.SuccessResponse{meta=dto.PageMeta}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/orders [get]
+// @Security BearerAuth
+func (h *OrderHandler) ListOrdersHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter dto.OrderFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := validate.Struct(filter); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		orders, meta, err := h.OrderService.ListOrders(filter)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidOrderFilter) {
+				utils.JSONError(c, 400, err.Error())
+				return
+			}
+			utils.JSONError(c, 500, "Failed to get orders")
+			return
+		}
+		utils.JSONSuccessWithMeta(c, orders, meta, "Order list")
+	}
+}
+
+// UpdateOrderStatusHandler godoc
+// @Summary Update order status
+// @Tags Order
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body dto.UpdateOrderStatusRequest true "Status data"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/orders/{id}/status [put]
+// @Security BearerAuth
+func (h *OrderHandler) UpdateOrderStatusHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid order id")
+			return
+		}
+		var req dto.UpdateOrderStatusRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		userID, _ := c.Get("userID")
+		order, err := h.OrderService.UpdateStatus(id, models.OrderStatus(req.Status), userID.(uint), req.Note)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrOrderNotFound):
+				utils.JSONError(c, 404, "Order not found")
+			case errors.Is(err, service.ErrInvalidOrderTransition),
+				errors.Is(err, service.ErrOrderStockNotRestorable):
+				utils.JSONError(c, 409, err.Error())
+			case errors.Is(err, service.ErrInvalidOrderStatus):
+				utils.JSONError(c, 400, err.Error())
+			default:
+				utils.JSONError(c, 500, "Failed to update order status")
+			}
+			return
+		}
+		utils.JSONSuccess(c, order, "Order status updated")
+	}
+}
+
+// OrderStatusHistoryHandler godoc
+// @Summary Get order status history
+// @Tags Order
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/orders/{id}/history [get]
+// @Security BearerAuth
+func (h *OrderHandler) OrderStatusHistoryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid order id")
+			return
+		}
+		histories, err := h.OrderService.GetStatusHistory(id)
+		if err != nil {
+			if errors.Is(err, service.ErrOrderNotFound) {
+				utils.JSONError(c, 404, "Order not found")
+				return
+			}
+			utils.JSONError(c, 500, "Failed to get order status history")
+			return
+		}
+		utils.JSONSuccess(c, histories, "Order status history")
+	}
+}
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 
 	"github.com/wahyuutomoputra/order-management/dto"
 	"github.com/wahyuutomoputra/order-management/models"
@@ -13,7 +15,7 @@ import (
 	"github.com/wahyuutomoputra/order-management/utils"
 )
 
-var productValidate = validator.New()
+var productValidate = utils.NewValidator()
 
 type ProductHandler struct {
 	ProductService *service.ProductService
@@ -43,6 +45,7 @@ func AdminOnly() gin.HandlerFunc {
 // @Success 201 {object} utils.SuccessResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /admin/products [post]
 // @Security BearerAuth
@@ -60,10 +63,17 @@ func (h *ProductHandler) CreateProductHandler() gin.HandlerFunc {
 		product := models.Product{
 			Name:  req.Name,
 			Price: req.Price,
-			Stock: req.Stock,
+			SKU:   normalizeSKU(req.SKU),
 		}
-		if err := h.ProductService.Create(&product); err != nil {
-			utils.JSONError(c, 500, "Failed to create product")
+		if req.Stock != nil {
+			product.Stock = *req.Stock
+		}
+		if req.ReorderThreshold != nil {
+			product.ReorderThreshold = *req.ReorderThreshold
+		}
+		userID, _ := c.Get("userID")
+		if err := h.ProductService.Create(&product, req.CategoryIDs, req.Tags, userID.(uint)); err != nil {
+			productError(c, err, "Failed to create product")
 			return
 		}
 		utils.JSONCreated(c, product, "Product created")
@@ -74,17 +84,40 @@ func (h *ProductHandler) CreateProductHandler() gin.HandlerFunc {
 // @Summary List products
 // @Tags Product
 // @Produce json
-// @Success 200 {object} utils.SuccessResponse
+// @Param q query string false "Search product name"
+// @Param min_price query string false "Minimum price"
+// @Param max_price query string false "Maximum price"
+// @Param in_stock query bool false "Only products with stock"
+// @Param category_id query int false "Category ID, includes its sub-categories"
+// @Param tag query string false "Tag name"
+// @Param sort query string false "Sort field, prefix with - for descending" Enums(name, -name, price, -price, stock, -stock, id, -id)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.SuccessResponse{meta=dto.PageMeta}
+// @Failure 400 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /products [get]
 func (h *ProductHandler) ListProductHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		products, err := h.ProductService.FindAll()
+		var filter dto.ProductFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(filter); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		products, meta, err := h.ProductService.List(filter)
 		if err != nil {
+			if errors.Is(err, service.ErrInvalidProductFilter) {
+				utils.JSONError(c, 400, err.Error())
+				return
+			}
 			utils.JSONError(c, 500, "Failed to get products")
 			return
 		}
-		utils.JSONSuccess(c, products, "Product list")
+		utils.JSONSuccessWithMeta(c, products, meta, "Product list")
 	}
 }
 
@@ -92,8 +125,12 @@ func (h *ProductHandler) ListProductHandler() gin.HandlerFunc {
 // @Summary Get product by ID
 // @Tags Product
 // @Produce json
+// @Description Stock counts only active warehouses, i.e. what can still be ordered.
+// @Description The response includes an ETag header with the product version; send it back in If-None-Match to get 304 when the product has not changed
 // @Param id path int true "Product ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} utils.SuccessResponse
+// @Success 304 "Not modified"
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Router /products/{id} [get]
@@ -109,6 +146,14 @@ func (h *ProductHandler) GetProductHandler() gin.HandlerFunc {
 			utils.JSONError(c, 404, "Product not found")
 			return
 		}
+		etag := productETag(product.Version)
+		c.Header("ETag", etag)
+		if match := c.GetHeader("If-None-Match"); match != "" {
+			if version, ok := parseETag(match); ok && version == product.Version {
+				c.Status(http.StatusNotModified)
+				return
+			}
+		}
 		utils.JSONSuccess(c, product, "Product detail")
 	}
 }
@@ -118,12 +163,16 @@ func (h *ProductHandler) GetProductHandler() gin.HandlerFunc {
 // @Tags Product
 // @Accept json
 // @Produce json
+// @Description The product version is required, either in the If-Match header (ETag from GET /products/{id}) or in the version field. If-Match: * updates regardless of version. If the product was modified by another request, the 409 response contains its current representation. Stock is the total across all warehouses and is rejected with 409 when the product is stocked in several warehouses; use POST /admin/products/{id}/stock to adjust a single warehouse.
 // @Param id path int true "Product ID"
+// @Param If-Match header string false "ETag of the product being updated, or * for any version"
 // @Param data body dto.ProductRequest true "Product data"
 // @Success 200 {object} utils.SuccessResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 428 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /admin/products/{id} [put]
 // @Security BearerAuth
@@ -143,6 +192,15 @@ func (h *ProductHandler) UpdateProductHandler() gin.HandlerFunc {
 			utils.JSONError(c, 400, err.Error())
 			return
 		}
+		version, ok, err := expectedVersion(c, req.Version)
+		if err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		if !ok {
+			utils.JSONError(c, http.StatusPreconditionRequired, "If-Match header or version is required")
+			return
+		}
 		product, err := h.ProductService.FindByID(id)
 		if err != nil {
 			utils.JSONError(c, 404, "Product not found")
@@ -150,17 +208,203 @@ func (h *ProductHandler) UpdateProductHandler() gin.HandlerFunc {
 		}
 		product.Name = req.Name
 		product.Price = req.Price
-		product.Stock = req.Stock
-		if err := h.ProductService.Update(product); err != nil {
-			utils.JSONError(c, 500, "Failed to update product")
+		if req.SKU != nil {
+			product.SKU = normalizeSKU(req.SKU)
+		}
+		if req.ReorderThreshold != nil {
+			product.ReorderThreshold = *req.ReorderThreshold
+		}
+		userID, _ := c.Get("userID")
+		if err := h.ProductService.Update(product, version, req.CategoryIDs, req.Tags, req.Stock, userID.(uint)); err != nil {
+			if errors.Is(err, service.ErrVersionConflict) {
+				h.versionConflict(c, id)
+				return
+			}
+			productError(c, err, "Failed to update product")
+			return
+		}
+		c.Header("ETag", productETag(product.Version))
+		utils.JSONSuccess(c, product, "Product updated")
+	}
+}
+
+// PatchProductHandler godoc
+// @Summary Partially update product
+// @Description JSON Merge Patch (RFC 7396): only the fields present are validated and changed. null clears sku, category_ids and tags. The product version is optional, via If-Match (or * for any version) or the version field; if the product was modified by another request, the 409 response contains its current representation. Stock is the total across all warehouses and is rejected with 409 when the product is stocked in several warehouses; use POST /admin/products/{id}/stock to adjust a single warehouse.
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param If-Match header string false "ETag of the product being updated, or * for any version"
+// @Param data body dto.ProductPatchRequest true "Fields to change"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id} [patch]
+// @Security BearerAuth
+func (h *ProductHandler) PatchProductHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ProductPatchRequest
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		body, err := c.GetRawData()
+		if err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+			utils.JSONError(c, 400, "Request body must be a JSON object")
+			return
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		for _, field := range []string{"name", "price", "stock", "reorder_threshold"} {
+			if isJSONNull(fields[field]) {
+				utils.JSONError(c, 400, field+" cannot be null")
+				return
+			}
+		}
+		if err := productValidate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		version, hasVersion, err := expectedVersion(c, req.Version)
+		if err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		product, err := h.ProductService.FindByID(id)
+		if err != nil {
+			utils.JSONError(c, 404, "Product not found")
 			return
 		}
+		if !hasVersion {
+			version = product.Version
+		}
+		if req.Name != nil {
+			product.Name = *req.Name
+		}
+		if req.Price != nil {
+			product.Price = *req.Price
+		}
+		if req.ReorderThreshold != nil {
+			product.ReorderThreshold = *req.ReorderThreshold
+		}
+		if _, ok := fields["sku"]; ok {
+			product.SKU = normalizeSKU(req.SKU)
+		}
+		// null pada relasi berarti dikosongkan, field yang tidak dikirim tetap nil (tidak diubah)
+		if isJSONNull(fields["category_ids"]) {
+			req.CategoryIDs = []uint{}
+		}
+		if isJSONNull(fields["tags"]) {
+			req.Tags = []string{}
+		}
+		userID, _ := c.Get("userID")
+		if err := h.ProductService.Update(product, version, req.CategoryIDs, req.Tags, req.Stock, userID.(uint)); err != nil {
+			if errors.Is(err, service.ErrVersionConflict) {
+				h.versionConflict(c, id)
+				return
+			}
+			productError(c, err, "Failed to update product")
+			return
+		}
+		c.Header("ETag", productETag(product.Version))
 		utils.JSONSuccess(c, product, "Product updated")
 	}
 }
 
+// isJSONNull mengecek apakah field JSON dikirim dengan nilai null
+func isJSONNull(raw json.RawMessage) bool {
+	return raw != nil && string(raw) == "null"
+}
+
+// versionConflict mengirim 409 beserta produk terkini dan ETag-nya agar client bisa
+// menggabungkan perubahan lalu mencoba lagi
+func (h *ProductHandler) versionConflict(c *gin.Context, id uint) {
+	current, err := h.ProductService.FindByID(id)
+	if err != nil {
+		utils.JSONError(c, 404, "Product not found")
+		return
+	}
+	c.Header("ETag", productETag(current.Version))
+	utils.JSONErrorWithData(c, 409, service.ErrVersionConflict.Error(), current)
+}
+
+// productETag membentuk strong ETag dari versi produk
+func productETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// parseETag membaca versi dari ETag, menerima bentuk weak (W/"3") maupun tanpa kutip
+func parseETag(etag string) (uint, bool) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	version, err := strconv.ParseUint(strings.Trim(etag, `"`), 10, 64)
+	if err != nil || version == 0 {
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// expectedVersion mengambil versi yang diharapkan client dari header If-Match, atau dari
+// field version jika header tidak dikirim. Mengembalikan false jika keduanya kosong.
+// If-Match: * cocok dengan versi mana pun (RFC 9110) sehingga menjadi service.AnyVersion.
+func expectedVersion(c *gin.Context, bodyVersion *uint) (uint, bool, error) {
+	if match := c.GetHeader("If-Match"); match != "" {
+		if strings.TrimSpace(match) == "*" {
+			return service.AnyVersion, true, nil
+		}
+		version, ok := parseETag(match)
+		if !ok {
+			return 0, false, errors.New("Invalid If-Match header")
+		}
+		return version, true, nil
+	}
+	if bodyVersion != nil {
+		return *bodyVersion, true, nil
+	}
+	return 0, false, nil
+}
+
+// productError memetakan error dari ProductService saat create/update produk ke response HTTP
+func productError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound), errors.Is(err, service.ErrStockManagedByVariants):
+		utils.JSONError(c, 400, err.Error())
+	case errors.Is(err, service.ErrProductNotFound):
+		utils.JSONError(c, 404, "Product not found")
+	case errors.Is(err, service.ErrProductSKUTaken), errors.Is(err, service.ErrInsufficientStock),
+		errors.Is(err, service.ErrNoActiveWarehouse), errors.Is(err, service.ErrStockInMultipleWarehouses):
+		utils.JSONError(c, 409, err.Error())
+	default:
+		utils.JSONError(c, 500, fallback)
+	}
+}
+
+// normalizeSKU mengubah SKU kosong menjadi nil agar tersimpan sebagai NULL
+func normalizeSKU(sku *string) *string {
+	if sku == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*sku)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
 // DeleteProductHandler godoc
-// @Summary Delete product
+// @Summary Archive product
+// @Description Soft delete a product: it is hidden from the catalogue and cannot be ordered, but stays resolvable from order history
 // @Tags Product
 // @Produce json
 // @Param id path int true "Product ID"
@@ -187,7 +431,244 @@ func (h *ProductHandler) DeleteProductHandler() gin.HandlerFunc {
 			utils.JSONError(c, 500, "Failed to delete product")
 			return
 		}
-		utils.JSONSuccess(c, nil, "Product deleted")
+		utils.JSONSuccess(c, nil, "Product archived")
+	}
+}
+
+// ListArchivedProductHandler godoc
+// @Summary List archived products
+// @Tags Product
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.SuccessResponse{meta=dto.PageMeta}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/archived [get]
+// @Security BearerAuth
+func (h *ProductHandler) ListArchivedProductHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page dto.PageQuery
+		if err := c.ShouldBindQuery(&page); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(page); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		products, meta, err := h.ProductService.ListArchived(page)
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to get archived products")
+			return
+		}
+		utils.JSONSuccessWithMeta(c, products, meta, "Archived product list")
+	}
+}
+
+// RestoreProductHandler godoc
+// @Summary Restore archived product
+// @Tags Product
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/restore [post]
+// @Security BearerAuth
+func (h *ProductHandler) RestoreProductHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		product, err := h.ProductService.Restore(id)
+		if err != nil {
+			if errors.Is(err, service.ErrProductNotFound) {
+				utils.JSONError(c, 404, "Archived product not found")
+				return
+			}
+			utils.JSONError(c, 500, "Failed to restore product")
+			return
+		}
+		utils.JSONSuccess(c, product, "Product restored")
+	}
+}
+
+// PurgeProductHandler godoc
+// @Summary Permanently delete product
+// @Description Permanently remove a product that is not referenced by any order
+// @Tags Product
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/purge [delete]
+// @Security BearerAuth
+func (h *ProductHandler) PurgeProductHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := h.ProductService.Purge(id); err != nil {
+			switch {
+			case errors.Is(err, service.ErrProductNotFound):
+				utils.JSONError(c, 404, "Product not found")
+			case errors.Is(err, service.ErrProductReferenced):
+				utils.JSONError(c, 409, err.Error())
+			default:
+				utils.JSONError(c, 500, "Failed to purge product")
+			}
+			return
+		}
+		utils.JSONSuccess(c, nil, "Product purged")
+	}
+}
+
+// CreateVariantHandler godoc
+// @Summary Create product variant
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param data body dto.VariantRequest true "Variant data"
+// @Success 201 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/variants [post]
+// @Security BearerAuth
+func (h *ProductHandler) CreateVariantHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		req, ok := bindVariantRequest(c)
+		if !ok {
+			return
+		}
+		userID, _ := c.Get("userID")
+		variant, err := h.ProductService.CreateVariant(id, req, userID.(uint))
+		if err != nil {
+			variantError(c, err, "Failed to create variant")
+			return
+		}
+		utils.JSONCreated(c, variant, "Variant created")
+	}
+}
+
+// UpdateVariantHandler godoc
+// @Summary Update product variant
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant_id path int true "Variant ID"
+// @Param data body dto.VariantRequest true "Variant data"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/variants/{variant_id} [put]
+// @Security BearerAuth
+func (h *ProductHandler) UpdateVariantHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id, variantID uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := parseUintParam(c, "variant_id", &variantID); err != nil {
+			utils.JSONError(c, 400, "Invalid variant id")
+			return
+		}
+		req, ok := bindVariantRequest(c)
+		if !ok {
+			return
+		}
+		userID, _ := c.Get("userID")
+		variant, err := h.ProductService.UpdateVariant(id, variantID, req, userID.(uint))
+		if err != nil {
+			variantError(c, err, "Failed to update variant")
+			return
+		}
+		utils.JSONSuccess(c, variant, "Variant updated")
+	}
+}
+
+// DeleteVariantHandler godoc
+// @Summary Delete product variant
+// @Tags Product
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant_id path int true "Variant ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/{id}/variants/{variant_id} [delete]
+// @Security BearerAuth
+func (h *ProductHandler) DeleteVariantHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id, variantID uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := parseUintParam(c, "variant_id", &variantID); err != nil {
+			utils.JSONError(c, 400, "Invalid variant id")
+			return
+		}
+		userID, _ := c.Get("userID")
+		if err := h.ProductService.DeleteVariant(id, variantID, userID.(uint)); err != nil {
+			variantError(c, err, "Failed to delete variant")
+			return
+		}
+		utils.JSONSuccess(c, nil, "Variant deleted")
+	}
+}
+
+func bindVariantRequest(c *gin.Context) (dto.VariantRequest, bool) {
+	var req dto.VariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.JSONError(c, 400, "Invalid request")
+		return req, false
+	}
+	if err := productValidate.Struct(req); err != nil {
+		utils.JSONError(c, 400, err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+func variantError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrProductNotFound):
+		utils.JSONError(c, 404, "Product not found")
+	case errors.Is(err, service.ErrVariantNotFound):
+		utils.JSONError(c, 404, "Product variant not found")
+	case errors.Is(err, service.ErrVariantSKUTaken), errors.Is(err, service.ErrInsufficientStock),
+		errors.Is(err, service.ErrNoActiveWarehouse):
+		utils.JSONError(c, 409, err.Error())
+	default:
+		utils.JSONError(c, 500, fallback)
 	}
 }
 
@@ -202,3 +683,18 @@ func parseUintParam(c *gin.Context, key string, out *uint) error {
 	*out = uint(id64)
 	return nil
 }
+
+// parseUintQuery membaca query parameter opsional, nilai kosong menjadi 0
+func parseUintQuery(c *gin.Context, key string, out *uint) error {
+	value := c.Query(key)
+	if value == "" {
+		*out = 0
+		return nil
+	}
+	id64, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return err
+	}
+	*out = uint(id64)
+	return nil
+}
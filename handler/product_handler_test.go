@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"github.com/wahyuutomoputra/order-management/service"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestExpectedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	three := uint(3)
+	tests := []struct {
+		ifMatch     string
+		bodyVersion *uint
+		want        uint
+		wantOK      bool
+		wantErr     bool
+	}{
+		{`"4"`, nil, 4, true, false},
+		{`W/"4"`, nil, 4, true, false},
+		{`4`, &three, 4, true, false},
+		// If-Match: * cocok dengan versi mana pun (RFC 9110)
+		{`*`, nil, service.AnyVersion, true, false},
+		{` * `, &three, service.AnyVersion, true, false},
+		{``, &three, 3, true, false},
+		{``, nil, 0, false, false},
+		{`"0"`, nil, 0, false, true},
+		{`"abc"`, nil, 0, false, true},
+		{`"1", "2"`, nil, 0, false, true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("PUT", "/products/1", nil)
+		if tt.ifMatch != "" {
+			c.Request.Header.Set("If-Match", tt.ifMatch)
+		}
+		got, ok, err := expectedVersion(c, tt.bodyVersion)
+		if (err != nil) != tt.wantErr || ok != tt.wantOK || got != tt.want {
+			t.Errorf("If-Match %q: got (%d, %v, %v), want (%d, %v, error %v)",
+				tt.ifMatch, got, ok, err, tt.want, tt.wantOK, tt.wantErr)
+		}
+	}
+}
+
+// newTestProductHandler membuat ProductHandler di atas database SQLite sementara dengan
+// satu gudang aktif, beserta router admin yang mengisi userID seperti middleware auth
+func newTestProductHandler(t *testing.T) (*gin.Engine, *service.ProductService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=foreign_keys(1)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.AutoMigrate(&models.Category{}, &models.Tag{}, &models.Product{}, &models.ProductVariant{},
+		&models.ProductImage{}, &models.Warehouse{}, &models.StockLevel{}, &models.InventoryMovement{},
+		&models.LowStockEvent{}); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	if err := db.Create(&models.Warehouse{Code: "MAIN", Name: "Main", Active: true}).Error; err != nil {
+		t.Fatalf("create warehouse: %v", err)
+	}
+	products := service.NewProductService(repository.NewProductRepository(db), repository.NewInventoryRepository(db),
+		repository.NewCategoryRepository(db), repository.NewTagRepository(db))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userID", uint(1)) })
+	h := NewProductHandler(products)
+	router.PUT("/admin/products/:id", h.UpdateProductHandler())
+	router.PATCH("/admin/products/:id", h.PatchProductHandler())
+	return router, products
+}
+
+func serveJSON(router *gin.Engine, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpdateProductVersionConflictReturnsCurrent(t *testing.T) {
+	router, products := newTestProductHandler(t)
+	product := models.Product{Name: "Teh", Price: models.NewMoney(1000)}
+	if err := products.Create(&product, nil, nil, 1); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	path := "/admin/products/" + strconv.FormatUint(uint64(product.ID), 10)
+
+	// admin pertama menyimpan perubahan dengan versi 1
+	if w := serveJSON(router, "PUT", path, `{"name": "Teh Manis", "price": "10.00"}`, map[string]string{"If-Match": `"1"`}); w.Code != 200 {
+		t.Fatalf("first update status = %d, body %s", w.Code, w.Body)
+	}
+	// admin kedua masih memegang versi 1
+	w := serveJSON(router, "PUT", path, `{"name": "Teh Tawar", "price": "12.00"}`, map[string]string{"If-Match": `"1"`})
+	if w.Code != 409 {
+		t.Fatalf("stale update status = %d, want 409; body %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Error string
+		Data  models.Product
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode 409 body: %v", err)
+	}
+	if resp.Error != service.ErrVersionConflict.Error() || resp.Data.Name != "Teh Manis" || resp.Data.Version != 2 {
+		t.Errorf("409 body = %+v, want current product %q at version 2", resp, "Teh Manis")
+	}
+	if etag := w.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("409 ETag = %s, want \"2\"", etag)
+	}
+	current, err := products.FindByID(product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Name != "Teh Manis" {
+		t.Errorf("stale update overwrote name to %q", current.Name)
+	}
+}
+
+func TestPatchProductMergePatchNulls(t *testing.T) {
+	router, products := newTestProductHandler(t)
+	sku := "PATCH-1"
+	product := models.Product{Name: "Kopi", SKU: &sku, Price: models.NewMoney(1500), Stock: 5, ReorderThreshold: 2}
+	if err := products.Create(&product, nil, []string{"minuman", "panas"}, 1); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	path := "/admin/products/" + strconv.FormatUint(uint64(product.ID), 10)
+
+	// null menghapus sku dan tags; field yang tidak dikirim tidak berubah
+	w := serveJSON(router, "PATCH", path, `{"sku": null, "tags": null, "stock": 0}`, nil)
+	if w.Code != 200 {
+		t.Fatalf("PATCH status = %d, body %s", w.Code, w.Body)
+	}
+	got, err := products.FindByID(product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SKU != nil || len(got.Tags) != 0 {
+		t.Errorf("after null patch sku = %v tags = %v, want both cleared", got.SKU, got.Tags)
+	}
+	if got.Name != "Kopi" || got.Price != models.NewMoney(1500) || got.ReorderThreshold != 2 || got.Stock != 0 {
+		t.Errorf("after patch name %q price %v threshold %d stock %d, want Kopi 15.00 2 0",
+			got.Name, got.Price, got.ReorderThreshold, got.Stock)
+	}
+	if etag := w.Header().Get("ETag"); etag != productETag(got.Version) {
+		t.Errorf("ETag = %s, want %s", etag, productETag(got.Version))
+	}
+
+	// field wajib tidak boleh null dan tidak mengubah apa pun
+	for _, body := range []string{`{"name": null}`, `{"price": null}`, `{"stock": null}`, `{"reorder_threshold": null}`} {
+		if w := serveJSON(router, "PATCH", path, body, nil); w.Code != 400 {
+			t.Errorf("PATCH %s status = %d, want 400", body, w.Code)
+		}
+	}
+	for _, body := range []string{`null`, `[]`, `{"name": "K"}`} {
+		if w := serveJSON(router, "PATCH", path, body, nil); w.Code != 400 {
+			t.Errorf("PATCH %s status = %d, want 400", body, w.Code)
+		}
+	}
+	after, err := products.FindByID(product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Version != got.Version || after.Name != "Kopi" {
+		t.Errorf("rejected patches changed product to %q version %d", after.Name, after.Version)
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/service"
+	"github.com/wahyuutomoputra/order-management/utils"
+)
+
+// ImportProductsHandler godoc
+// @Summary Import products from CSV or JSON Lines
+// @Description Upsert products by SKU from an uploaded file in one transaction. Columns/keys: sku, name, price, stock, reorder_threshold, category_ids, tags (CSV lists are separated by "|"). Empty fields leave the product unchanged; name and price are required for new SKUs. If any row fails nothing is saved and the per-row errors are returned with status 422. dry_run=true validates and reports without saving.
+// @Tags Product
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or JSONL file"
+// @Param format query string false "csv or jsonl, defaults to the file extension"
+// @Param dry_run query bool false "Validate only, do not save"
+// @Success 200 {object} utils.SuccessResponse{data=dto.ImportReport}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.ErrorResponse{data=dto.ImportReport}
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/import [post]
+// @Security BearerAuth
+func (h *ProductHandler) ImportProductsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query dto.ProductImportQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(query); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		header, err := c.FormFile("file")
+		if err != nil {
+			utils.JSONError(c, 400, "File is required")
+			return
+		}
+		format := query.Format
+		if format == "" {
+			format = importFormatFromName(header.Filename)
+		}
+		if format == "" {
+			utils.JSONError(c, 400, "Unknown file format, use format=csv or format=jsonl")
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			utils.JSONError(c, 400, "Failed to read file")
+			return
+		}
+		defer file.Close()
+
+		userID, _ := c.Get("userID")
+		report, err := h.ProductService.Import(file, format, query.DryRun, userID.(uint))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidImportFile) || errors.Is(err, service.ErrImportTooLarge) {
+				utils.JSONError(c, 400, err.Error())
+				return
+			}
+			utils.JSONError(c, 500, "Failed to import products")
+			return
+		}
+		if len(report.Errors) > 0 {
+			utils.JSONErrorWithData(c, http.StatusUnprocessableEntity, "Import has invalid rows, nothing was saved", report)
+			return
+		}
+		message := "Products imported"
+		if report.DryRun {
+			message = "Dry run succeeded, nothing was saved"
+		}
+		utils.JSONSuccess(c, report, message)
+	}
+}
+
+// ExportProductsHandler godoc
+// @Summary Export products as CSV or JSON Lines
+// @Description Stream the active catalogue in the same format accepted by the import endpoint.
+// @Description Products without a SKU cannot be re-imported and are left out; their count is returned in the X-Export-Skipped-Without-SKU header.
+// @Tags Product
+// @Produce text/csv,application/x-ndjson
+// @Param format query string false "csv (default) or jsonl"
+// @Success 200 {file} file
+// @Header 200 {integer} X-Export-Skipped-Without-SKU "Number of active products left out because they have no SKU"
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/products/export [get]
+// @Security BearerAuth
+func (h *ProductHandler) ExportProductsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query dto.ProductExportQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(query); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		format := query.Format
+		if format == "" {
+			format = service.ImportFormatCSV
+		}
+		contentType := "text/csv"
+		if format == service.ImportFormatJSONL {
+			contentType = "application/x-ndjson"
+		}
+		skipped, err := h.ProductService.CountExportSkipped()
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to export products")
+			return
+		}
+		filename := "products-" + time.Now().Format("20060102-150405") + "." + format
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Header("X-Export-Skipped-Without-SKU", strconv.FormatInt(skipped, 10))
+		c.Status(http.StatusOK)
+		// header sudah terkirim, error di tengah stream hanya bisa memutus response
+		if err := h.ProductService.Export(c.Writer, format); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+		}
+	}
+}
+
+// importFormatFromName menentukan format import dari ekstensi file
+func importFormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return service.ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		return service.ImportFormatJSONL
+	}
+	return ""
+}
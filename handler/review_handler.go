@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/service"
+	"github.com/wahyuutomoputra/order-management/utils"
+)
+
+type ReviewHandler struct {
+	ReviewService *service.ReviewService
+}
+
+func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{ReviewService: reviewService}
+}
+
+// CreateReviewHandler godoc
+// @Summary Review a product
+// @Description Post a 1-5 rating and review text. Only allowed for products in one of your delivered orders, once per product.
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param data body dto.ReviewRequest true "Review"
+// @Success 201 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /products/{id}/reviews [post]
+// @Security BearerAuth
+func (h *ReviewHandler) CreateReviewHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReviewRequest
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := productValidate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		userID, _ := c.Get("userID")
+		review, err := h.ReviewService.Create(id, userID.(uint), req)
+		if err != nil {
+			reviewError(c, err, "Failed to create review")
+			return
+		}
+		utils.JSONCreated(c, review, "Review created")
+	}
+}
+
+// ListProductReviewsHandler godoc
+// @Summary List product reviews
+// @Description Published reviews of a product, newest first. The aggregate rating is available on the product itself.
+// @Tags Review
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.SuccessResponse{meta=dto.PageMeta}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /products/{id}/reviews [get]
+func (h *ReviewHandler) ListProductReviewsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page dto.PageQuery
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid product id")
+			return
+		}
+		if err := c.ShouldBindQuery(&page); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(page); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		reviews, meta, err := h.ReviewService.ListPublished(id, page)
+		if err != nil {
+			reviewError(c, err, "Failed to get reviews")
+			return
+		}
+		utils.JSONSuccessWithMeta(c, reviews, meta, "Product reviews")
+	}
+}
+
+// ListReviewsHandler godoc
+// @Summary List reviews for moderation
+// @Tags Review
+// @Produce json
+// @Param product_id query int false "Product ID"
+// @Param status query string false "Review status" Enums(published, hidden)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.SuccessResponse{meta=dto.PageMeta}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/reviews [get]
+// @Security BearerAuth
+func (h *ReviewHandler) ListReviewsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var filter dto.ReviewFilter
+		if err := c.ShouldBindQuery(&filter); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(filter); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		reviews, meta, err := h.ReviewService.List(filter)
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to get reviews")
+			return
+		}
+		utils.JSONSuccessWithMeta(c, reviews, meta, "Reviews")
+	}
+}
+
+// UpdateReviewStatusHandler godoc
+// @Summary Moderate review
+// @Description Publish or hide a review. Hidden reviews are not shown on the product and are excluded from its rating.
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param data body dto.ReviewStatusRequest true "New status"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/reviews/{id}/status [put]
+// @Security BearerAuth
+func (h *ReviewHandler) UpdateReviewStatusHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReviewStatusRequest
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid review id")
+			return
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := productValidate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		review, err := h.ReviewService.UpdateStatus(id, models.ReviewStatus(req.Status))
+		if err != nil {
+			reviewError(c, err, "Failed to update review")
+			return
+		}
+		utils.JSONSuccess(c, review, "Review updated")
+	}
+}
+
+// DeleteReviewHandler godoc
+// @Summary Delete review
+// @Tags Review
+// @Produce json
+// @Param id path int true "Review ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/reviews/{id} [delete]
+// @Security BearerAuth
+func (h *ReviewHandler) DeleteReviewHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid review id")
+			return
+		}
+		if err := h.ReviewService.Delete(id); err != nil {
+			reviewError(c, err, "Failed to delete review")
+			return
+		}
+		utils.JSONSuccess(c, nil, "Review deleted")
+	}
+}
+
+// reviewError memetakan error dari ReviewService ke response HTTP
+func reviewError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrProductNotFound):
+		utils.JSONError(c, 404, "Product not found")
+	case errors.Is(err, service.ErrReviewNotFound):
+		utils.JSONError(c, 404, err.Error())
+	case errors.Is(err, service.ErrNotVerifiedBuyer):
+		utils.JSONError(c, 403, err.Error())
+	case errors.Is(err, service.ErrReviewExists):
+		utils.JSONError(c, 409, err.Error())
+	default:
+		utils.JSONError(c, 500, fallback)
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/service"
+	"github.com/wahyuutomoputra/order-management/utils"
+)
+
+type SearchHandler struct {
+	SearchService *service.SearchService
+}
+
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{SearchService: searchService}
+}
+
+// SearchProductsHandler godoc
+// @Summary Search products
+// @Description Full-text search on product name, SKU and tags with prefix and typo-tolerant matching. Results are ranked by relevance, then in-stock products first. highlight contains the HTML-escaped name with matched words wrapped in <mark>.
+// @Tags Product
+// @Produce json
+// @Param q query string true "Search query"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} utils.SuccessResponse{meta=dto.PageMeta}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /products/search [get]
+func (h *SearchHandler) SearchProductsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query dto.ProductSearchQuery
+		if err := c.ShouldBindQuery(&query); err != nil {
+			utils.JSONError(c, 400, "Invalid query")
+			return
+		}
+		if err := productValidate.Struct(query); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		hits, meta, err := h.SearchService.Search(c.Request.Context(), query)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidSearchQuery) {
+				utils.JSONError(c, 400, err.Error())
+				return
+			}
+			utils.JSONError(c, 500, "Failed to search products")
+			return
+		}
+		utils.JSONSuccessWithMeta(c, hits, meta, "Search results")
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/service"
+	"github.com/wahyuutomoputra/order-management/utils"
+)
+
+type WarehouseHandler struct {
+	WarehouseService *service.WarehouseService
+}
+
+func NewWarehouseHandler(warehouseService *service.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{WarehouseService: warehouseService}
+}
+
+// ListWarehousesHandler godoc
+// @Summary List warehouses
+// @Tags Warehouse
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/warehouses [get]
+// @Security BearerAuth
+func (h *WarehouseHandler) ListWarehousesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		warehouses, err := h.WarehouseService.FindAll()
+		if err != nil {
+			utils.JSONError(c, 500, "Failed to get warehouses")
+			return
+		}
+		utils.JSONSuccess(c, warehouses, "Warehouse list")
+	}
+}
+
+// CreateWarehouseHandler godoc
+// @Summary Create warehouse
+// @Tags Warehouse
+// @Accept json
+// @Produce json
+// @Param data body dto.WarehouseRequest true "Warehouse data"
+// @Success 201 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/warehouses [post]
+// @Security BearerAuth
+func (h *WarehouseHandler) CreateWarehouseHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.WarehouseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		warehouse, err := h.WarehouseService.Create(req)
+		if err != nil {
+			warehouseError(c, err, "Failed to create warehouse")
+			return
+		}
+		utils.JSONCreated(c, warehouse, "Warehouse created")
+	}
+}
+
+// UpdateWarehouseHandler godoc
+// @Summary Update warehouse
+// @Description Update a warehouse; inactive warehouses are skipped by order allocation
+// @Tags Warehouse
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param data body dto.WarehouseRequest true "Warehouse data"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /admin/warehouses/{id} [put]
+// @Security BearerAuth
+func (h *WarehouseHandler) UpdateWarehouseHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var id uint
+		if err := parseUintParam(c, "id", &id); err != nil {
+			utils.JSONError(c, 400, "Invalid warehouse id")
+			return
+		}
+		var req dto.WarehouseRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.JSONError(c, 400, "Invalid request")
+			return
+		}
+		if err := validate.Struct(req); err != nil {
+			utils.JSONError(c, 400, err.Error())
+			return
+		}
+		warehouse, err := h.WarehouseService.Update(id, req)
+		if err != nil {
+			warehouseError(c, err, "Failed to update warehouse")
+			return
+		}
+		utils.JSONSuccess(c, warehouse, "Warehouse updated")
+	}
+}
+
+func warehouseError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrWarehouseNotFound):
+		utils.JSONError(c, 404, err.Error())
+	case errors.Is(err, service.ErrWarehouseCodeTaken):
+		utils.JSONError(c, 409, err.Error())
+	default:
+		utils.JSONError(c, 500, fallback)
+	}
+}
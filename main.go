@@ -31,13 +31,16 @@ import (
 func main() {
 	fmt.Println("Starting Order Management API...")
 
+	// mata uang toko dipasang sebelum data apa pun dibaca atau dimigrasi
+	models.DefaultCurrency = config.LoadCurrency()
+
 	db, err := config.ConnectDB()
 	if err != nil {
 		log.Fatal("failed to connect database: ", err)
 	}
 	log.Println("Connected to database.")
 	// Auto migrate
-	if err := db.AutoMigrate(&models.User{}, &models.Product{}, &models.Order{}, &models.OrderItem{}); err != nil {
+	if err := config.Migrate(db); err != nil {
 		log.Fatalf("Database migration failed: %v", err)
 	} else {
 		log.Println("Database migration successful")
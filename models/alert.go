@@ -0,0 +1,25 @@
+package models
+
+// LowStockEvent dicatat saat perubahan stok membuat stok produk turun melewati
+// ReorderThreshold. Event dikirim ke notifier oleh dispatcher di background.
+type LowStockEvent struct {
+	ID          uint   `gorm:"primaryKey"`
+	ProductID   uint   `gorm:"index"`
+	ProductName string `gorm:"size:255"`
+	Stock       int    // stok setelah perubahan
+	Threshold   int
+	DeliveredAt int64 `gorm:"index"` // 0 selama belum berhasil dikirim
+	Attempts    int
+	LastError   string `gorm:"size:255"`
+	CreatedAt   int64
+}
+
+// EmailOutbox adalah email yang menunggu dikirim oleh proses pengirim email terpisah
+type EmailOutbox struct {
+	ID        uint   `gorm:"primaryKey"`
+	To        string `gorm:"size:255"`
+	Subject   string `gorm:"size:255"`
+	Body      string `gorm:"type:text"`
+	SentAt    int64  `gorm:"index"` // 0 selama belum dikirim
+	CreatedAt int64
+}
@@ -0,0 +1,12 @@
+package models
+
+// CartItem adalah satu baris keranjang belanja milik user
+type CartItem struct {
+	ID        uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"uniqueIndex:idx_cart_user_product"`
+	ProductID uint `gorm:"uniqueIndex:idx_cart_user_product"`
+	VariantID uint `gorm:"uniqueIndex:idx_cart_user_product"` // 0 jika produk tanpa varian
+	Quantity  int
+	CreatedAt int64
+	UpdatedAt int64
+}
@@ -0,0 +1,16 @@
+package models
+
+// Category adalah kategori produk yang bisa bertingkat (parent/child)
+type Category struct {
+	ID       uint       `gorm:"primaryKey"`
+	Name     string     `gorm:"size:100"`
+	Slug     string     `gorm:"size:120;uniqueIndex"`
+	ParentID *uint      `gorm:"index"`
+	Children []Category `gorm:"-"` // diisi saat membangun tree kategori
+}
+
+// Tag adalah label bebas untuk produk
+type Tag struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:50;uniqueIndex"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// IdempotencyKey menyimpan hasil request yang dikirim dengan header Idempotency-Key
+// agar request ulang dengan key yang sama mendapat response yang sama
+type IdempotencyKey struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"uniqueIndex:idx_idempotency_user_key"`
+	Key          string `gorm:"size:255;uniqueIndex:idx_idempotency_user_key"`
+	RequestHash  string `gorm:"size:64"`
+	StatusCode   int    // 0 selama request pertama masih diproses
+	ResponseBody []byte
+	// LockedUntil adalah batas waktu (unix) request yang sedang memproses key. Setelah
+	// lewat, key yang belum selesai boleh diambil alih request lain, misal karena proses
+	// sebelumnya mati. Nilainya juga menjadi token pemilik key saat menyimpan response.
+	LockedUntil int64
+	CreatedAt   int64
+	ExpiresAt   int64 `gorm:"index"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "gorm.io/gorm"
+
+// ImageURL membentuk URL publik gambar dari storage key. Diisi saat aplikasi start
+// sesuai storage yang dipakai.
+var ImageURL = func(key string) string {
+	return "/images/" + key
+}
+
+// ProductImage adalah gambar produk yang filenya tersimpan di storage
+type ProductImage struct {
+	ID        uint `gorm:"primaryKey"`
+	ProductID uint `gorm:"index:idx_product_image_position"`
+	// StorageKey adalah path file di storage, unik per upload
+	StorageKey  string `gorm:"size:255;uniqueIndex"`
+	ContentType string `gorm:"size:50"`
+	Size        int64
+	// Position adalah urutan tampil gambar, dimulai dari 1
+	Position int `gorm:"index:idx_product_image_position"`
+	// IsPrimary menandai gambar utama; setiap produk yang memiliki gambar punya tepat satu
+	IsPrimary bool
+	URL       string `gorm:"-"`
+	CreatedAt int64
+}
+
+// AfterFind mengisi URL publik gambar setelah dibaca dari database
+func (i *ProductImage) AfterFind(tx *gorm.DB) error {
+	i.URL = ImageURL(i.StorageKey)
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+// MovementReason adalah alasan perubahan stok pada ledger inventori
+type MovementReason string
+
+const (
+	MovementSale       MovementReason = "sale"
+	MovementRestock    MovementReason = "restock"
+	MovementAdjustment MovementReason = "adjustment"
+	MovementCancel     MovementReason = "cancel"
+)
+
+// InventoryMovement adalah satu baris ledger inventori yang bersifat append-only.
+// Jumlah Delta untuk sebuah produk (atau varian) selalu sama dengan stoknya.
+type InventoryMovement struct {
+	ID        uint  `gorm:"primaryKey"`
+	ProductID uint  `gorm:"index:idx_inventory_product_created"`
+	VariantID *uint `gorm:"index"`
+	// WarehouseID adalah gudang tempat stok berubah
+	WarehouseID uint `gorm:"index"`
+	// Delta positif menambah stok, negatif mengurangi stok
+	Delta  int
+	Reason MovementReason `gorm:"size:20"`
+	// ReferenceID menunjuk ke order untuk reason sale dan cancel
+	ReferenceID uint   `gorm:"index"`
+	ActorID     uint   // user yang menyebabkan perubahan stok
+	Note        string `gorm:"size:255"`
+	CreatedAt   int64  `gorm:"index:idx_inventory_product_created"`
+}
@@ -0,0 +1,244 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency adalah mata uang toko, diisi main dari config.LoadCurrency sebelum
+// database dibuka. Produk dan order menyimpan mata uangnya sendiri; data yang mata
+// uangnya berbeda dengan DefaultCurrency ditolak saat dibaca (ErrCurrencyMismatch).
+var DefaultCurrency = "IDR"
+
+// currencyMinorDigits berisi jumlah digit minor unit untuk mata uang yang tidak 2 digit
+var currencyMinorDigits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"BHD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// MinorDigits mengembalikan jumlah digit desimal minor unit sebuah mata uang
+func MinorDigits(currency string) int {
+	if digits, ok := currencyMinorDigits[currency]; ok {
+		return digits
+	}
+	return 2
+}
+
+// Money adalah nominal uang yang disimpan sebagai integer minor unit (misal sen) beserta
+// kode mata uangnya, sehingga penjumlahan tidak mengalami pembulatan seperti float64.
+// Di database hanya Amount yang disimpan di kolom nominal; mata uangnya disimpan di
+// kolom currency milik produk/order dan diisi kembali saat dibaca. Di JSON ditulis
+// sebagai string desimal, misal "12.34". Field bertipe Money perlu tag
+// swaggertype:"string" agar dokumentasi Swagger sesuai.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// ErrCurrencyMismatch dikembalikan saat data yang dibaca atau diterima memakai mata uang
+// yang berbeda dengan DefaultCurrency
+var ErrCurrencyMismatch = errors.New("money currency does not match store currency")
+
+var errInvalidMoney = errors.New("invalid money amount")
+
+// NewMoney membuat Money dalam DefaultCurrency dari nominal minor unit
+func NewMoney(amount int64) Money {
+	return Money{Amount: amount, Currency: DefaultCurrency}
+}
+
+// checkCurrency memastikan currency yang tersimpan sama dengan DefaultCurrency. Currency
+// kosong berarti kolom currency tidak ikut dibaca sehingga dianggap DefaultCurrency.
+func checkCurrency(currency string) error {
+	if currency != "" && currency != DefaultCurrency {
+		return fmt.Errorf("%w: stored in %s, store currency is %s", ErrCurrencyMismatch, currency, DefaultCurrency)
+	}
+	return nil
+}
+
+// withCurrency mengisi mata uang nominal yang dibaca dari database
+func (m *Money) withCurrency(currency string) {
+	if currency != "" {
+		m.Currency = currency
+	}
+}
+
+// ParseMoney mengubah string desimal seperti "12.34" menjadi Money tanpa melewati float.
+// Jumlah digit desimal tidak boleh melebihi minor unit DefaultCurrency.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	whole, frac, _ := strings.Cut(s, ".")
+	digits := MinorDigits(DefaultCurrency)
+	if whole == "" && frac == "" || len(frac) > digits || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, errInvalidMoney
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, errInvalidMoney
+	}
+	if negative {
+		amount = -amount
+	}
+	return NewMoney(amount), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+// String mengembalikan nominal dalam bentuk desimal, misal "12.34"
+func (m Money) String() string {
+	digits := MinorDigits(m.currency())
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := strconv.FormatInt(amount, 10)
+	if digits == 0 {
+		return sign + s
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currency()}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currency()}
+}
+
+// Mul mengalikan nominal dengan quantity
+func (m Money) Mul(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.currency()}
+}
+
+// MulRate mengalikan nominal dengan tarif (misal pajak 0.11) dan membulatkan ke minor unit terdekat
+func (m Money) MulRate(rate float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * rate)), Currency: m.currency()}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// MarshalJSON menulis nominal sebagai string desimal, misal "12.34"
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON menerima "12.34", 12.34, atau bentuk lama {"amount": "12.34", "currency": "IDR"}
+// selama currency-nya kosong atau sama dengan DefaultCurrency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		var obj struct {
+			Amount   json.RawMessage `json:"amount"`
+			Currency string          `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		if err := checkCurrency(obj.Currency); err != nil {
+			return err
+		}
+		parsed, err := ParseMoney(rawDecimal(obj.Amount))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+	parsed, err := ParseMoney(rawDecimal(data))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// rawDecimal mengambil teks angka dari JSON number atau JSON string
+func rawDecimal(data json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s
+	}
+	return string(data)
+}
+
+// Value menyimpan Money ke database sebagai integer minor unit
+func (m Money) Value() (driver.Value, error) {
+	if err := checkCurrency(m.Currency); err != nil {
+		return nil, err
+	}
+	return m.Amount, nil
+}
+
+// Scan membaca integer minor unit dari database. Mata uangnya diisi DefaultCurrency lalu
+// diganti dengan kolom currency milik baris tersebut oleh hook AfterFind produk/order.
+func (m *Money) Scan(value interface{}) error {
+	var amount int64
+	switch v := value.(type) {
+	case nil:
+		amount = 0
+	case int64:
+		amount = v
+	case []byte:
+		n, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return fmt.Errorf("scan money: %w", err)
+		}
+		amount = n
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("scan money: %w", err)
+		}
+		amount = n
+	default:
+		return fmt.Errorf("scan money: unsupported type %T", value)
+	}
+	*m = NewMoney(amount)
+	return nil
+}
+
+func (Money) GormDataType() string {
+	return "bigint"
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// withCurrency mengganti DefaultCurrency selama satu test
+func withCurrency(t *testing.T, currency string) {
+	t.Helper()
+	previous := DefaultCurrency
+	DefaultCurrency = currency
+	t.Cleanup(func() { DefaultCurrency = previous })
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		currency string
+		in       string
+		want     int64
+		wantErr  bool
+	}{
+		{"IDR", "12.34", 1234, false},
+		{"IDR", "12.3", 1230, false},
+		{"IDR", "12", 1200, false},
+		{"IDR", ".5", 50, false},
+		{"IDR", "0.01", 1, false},
+		{"IDR", " 7.25 ", 725, false},
+		{"IDR", "+1.00", 100, false},
+		{"IDR", "-12.34", -1234, false},
+		{"IDR", "-.5", -50, false},
+		{"IDR", "-0", 0, false},
+		// digit desimal melebihi minor unit ditolak, tidak dibulatkan
+		{"IDR", "12.345", 0, true},
+		{"IDR", "0.001", 0, true},
+		{"IDR", "", 0, true},
+		{"IDR", "-", 0, true},
+		{"IDR", ".", 0, true},
+		{"IDR", "abc", 0, true},
+		{"IDR", "1.2.3", 0, true},
+		{"IDR", "1e3", 0, true},
+		{"IDR", "--1", 0, true},
+		{"IDR", "1,000.00", 0, true},
+		{"IDR", "99999999999999999999", 0, true},
+		{"JPY", "1500", 1500, false},
+		{"JPY", "1.5", 0, true},
+		{"KWD", "1.234", 1234, false},
+		{"KWD", "1.2345", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency+" "+tt.in, func(t *testing.T) {
+			withCurrency(t, tt.currency)
+			got, err := ParseMoney(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseMoney(%q) = %d, want error", tt.in, got.Amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney(%q): %v", tt.in, err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		currency string
+		amount   int64
+		want     string
+	}{
+		{"IDR", 1234, "12.34"},
+		{"IDR", 5, "0.05"},
+		{"IDR", 50, "0.50"},
+		{"IDR", 0, "0.00"},
+		{"IDR", 100000, "1000.00"},
+		{"IDR", -5, "-0.05"},
+		{"IDR", -1234, "-12.34"},
+		{"JPY", 1500, "1500"},
+		{"JPY", -3, "-3"},
+		{"KWD", 1234, "1.234"},
+		{"KWD", 7, "0.007"},
+	}
+	for _, tt := range tests {
+		withCurrency(t, tt.currency)
+		if got := NewMoney(tt.amount).String(); got != tt.want {
+			t.Errorf("%s %d: String() = %q, want %q", tt.currency, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulRate(t *testing.T) {
+	tests := []struct {
+		amount int64
+		rate   float64
+		want   int64
+	}{
+		{1000, 0.11, 110},
+		// 110.55 dibulatkan ke atas, 110.44 ke bawah
+		{1005, 0.11, 111},
+		{1004, 0.11, 110},
+		// setengah dibulatkan menjauhi nol
+		{1, 0.5, 1},
+		{3, 0.5, 2},
+		{-1005, 0.11, -111},
+		{1234, 0, 0},
+		{1234, 1, 1234},
+	}
+	for _, tt := range tests {
+		if got := NewMoney(tt.amount).MulRate(tt.rate); got.Amount != tt.want {
+			t.Errorf("%d x %v = %d, want %d", tt.amount, tt.rate, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	withCurrency(t, "IDR")
+	data, err := json.Marshal(struct {
+		Price Money  `json:"price"`
+		Old   *Money `json:"old"`
+	}{Price: NewMoney(1234)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"price":"12.34","old":null}`; string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{`"12.34"`, 1234, false},
+		{`12.34`, 1234, false},
+		{`-1`, -100, false},
+		{`{"amount": "12.34", "currency": "IDR"}`, 1234, false},
+		{`{"amount": 12.34}`, 1234, false},
+		{`{"amount": "12.34", "currency": "USD"}`, 0, true},
+		{`"12.345"`, 0, true},
+		{`"abc"`, 0, true},
+	}
+	for _, tt := range tests {
+		var m Money
+		err := json.Unmarshal([]byte(tt.in), &m)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %d, want error", tt.in, m.Amount)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.in, err)
+			continue
+		}
+		if m.Amount != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, m.Amount, tt.want)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	for _, value := range []interface{}{int64(1234), []byte("1234"), "1234"} {
+		var m Money
+		if err := m.Scan(value); err != nil {
+			t.Fatalf("Scan(%#v): %v", value, err)
+		}
+		if m != NewMoney(1234) {
+			t.Errorf("Scan(%#v) = %+v, want 1234", value, m)
+		}
+		if v, _ := m.Value(); v != int64(1234) {
+			t.Errorf("Value() = %#v, want 1234", v)
+		}
+	}
+	var m Money
+	if err := m.Scan(1.5); err == nil {
+		t.Error("Scan(float64) should fail")
+	}
+}
+
+func TestMoneyCurrency(t *testing.T) {
+	withCurrency(t, "IDR")
+	if got := (Money{Amount: 1500, Currency: "JPY"}).String(); got != "1500" {
+		t.Errorf("JPY String() = %q, want 1500", got)
+	}
+	if got := NewMoney(1).Add(NewMoney(2)); got != (Money{Amount: 3, Currency: "IDR"}) {
+		t.Errorf("Add = %+v, want 3 IDR", got)
+	}
+	if _, err := (Money{Amount: 1, Currency: "USD"}).Value(); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Value() in USD: error = %v, want ErrCurrencyMismatch", err)
+	}
+
+	price := NewMoney(500)
+	product := Product{ID: 1, Currency: "IDR", Price: Money{Amount: 1000}, Variants: []ProductVariant{{Price: &price}}}
+	if err := product.AfterFind(nil); err != nil {
+		t.Fatalf("AfterFind: %v", err)
+	}
+	if product.Price.Currency != "IDR" || product.Variants[0].Price.Currency != "IDR" {
+		t.Errorf("AfterFind currencies = %q, %q, want IDR", product.Price.Currency, product.Variants[0].Price.Currency)
+	}
+	// data yang disimpan dengan mata uang lain tidak boleh dibaca sebagai mata uang toko
+	if err := (&Product{ID: 2, Currency: "USD"}).AfterFind(nil); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("product AfterFind in USD: error = %v, want ErrCurrencyMismatch", err)
+	}
+	if err := (&Order{ID: 3, Currency: "USD"}).AfterFind(nil); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("order AfterFind in USD: error = %v, want ErrCurrencyMismatch", err)
+	}
+}
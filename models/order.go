@@ -1,16 +1,138 @@
 package models
 
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// OrderStatus adalah status siklus hidup sebuah order
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusPacked    OrderStatus = "packed"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusRefunded  OrderStatus = "refunded"
+)
+
+// orderTransitions mendefinisikan perpindahan status yang diizinkan
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusPacked, OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusPacked:    {OrderStatusShipped, OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusShipped:   {OrderStatusDelivered},
+	OrderStatusDelivered: {OrderStatusRefunded},
+}
+
+// orderProgress adalah urutan status pada alur normal order
+var orderProgress = map[OrderStatus]int{
+	OrderStatusPending:   0,
+	OrderStatusPaid:      1,
+	OrderStatusPacked:    2,
+	OrderStatusShipped:   3,
+	OrderStatusDelivered: 4,
+}
+
+// Valid mengecek apakah status dikenal
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case OrderStatusPending, OrderStatusPaid, OrderStatusPacked, OrderStatusShipped,
+		OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo mengecek apakah status boleh berpindah ke status tujuan
+func (s OrderStatus) CanTransitionTo(to OrderStatus) bool {
+	for _, next := range orderTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAfter mengecek apakah status sudah melewati status lain pada alur normal order.
+// Status di luar alur normal (cancelled/refunded) selalu dianggap sudah lewat.
+func (s OrderStatus) IsAfter(other OrderStatus) bool {
+	rank, ok := orderProgress[s]
+	if !ok {
+		return true
+	}
+	return rank > orderProgress[other]
+}
+
 type Order struct {
-	ID        uint `gorm:"primaryKey"`
-	UserID    uint
-	CreatedAt int64
-	Items     []OrderItem
+	ID          uint        `gorm:"primaryKey"`
+	UserID      uint        `gorm:"index:idx_orders_user_created"`
+	Status      OrderStatus `gorm:"size:20;default:pending;index:idx_orders_status_created"`
+	Subtotal    Money       `swaggertype:"string"` // jumlah LineTotal semua item
+	Discount    Money       `swaggertype:"string"`
+	Tax         Money       `swaggertype:"string"`
+	ShippingFee Money       `swaggertype:"string"`
+	GrandTotal  Money       `gorm:"index" swaggertype:"string"` // total yang dibayar customer
+	CreatedAt   int64       `gorm:"index;index:idx_orders_user_created;index:idx_orders_status_created"`
+	Items       []OrderItem
+	// Currency adalah mata uang semua nominal order dan itemnya, diisi saat order dibuat
+	Currency string `gorm:"size:3;not null;default:''" example:"IDR"`
 }
 
 type OrderItem struct {
 	ID        uint `gorm:"primaryKey"`
-	OrderID   uint
-	ProductID uint
-	Quantity  int
-	Price     float64 // harga saat order
+	OrderID   uint `gorm:"index"`
+	ProductID uint `gorm:"index"`
+	// ProductName dan ProductSKU adalah snapshot produk saat order dibuat, sehingga
+	// perubahan produk setelahnya tidak mengubah riwayat order
+	ProductName string `gorm:"size:255"`
+	ProductSKU  string `gorm:"size:64"`
+	// VariantID, VariantSKU dan VariantOptions menyimpan varian yang dibeli saat order
+	VariantID      *uint
+	VariantSKU     string `gorm:"size:64"`
+	VariantOptions Attributes
+	// WarehouseID adalah gudang yang mengirim item ini. Satu baris order yang stoknya
+	// dipecah ke beberapa gudang disimpan sebagai beberapa OrderItem.
+	WarehouseID uint `gorm:"index"`
+	Quantity    int
+	Price       Money `swaggertype:"string"` // harga saat order
+	LineTotal   Money `swaggertype:"string"` // Price x Quantity
+}
+
+// OrderStatusHistory mencatat setiap perubahan status order
+type OrderStatusHistory struct {
+	ID         uint        `gorm:"primaryKey"`
+	OrderID    uint        `gorm:"index"`
+	FromStatus OrderStatus `gorm:"size:20"`
+	ToStatus   OrderStatus `gorm:"size:20"`
+	ChangedBy  uint        // user yang mengubah status
+	Note       string
+	CreatedAt  int64
+}
+
+// BeforeCreate mencatat mata uang order baru
+func (o *Order) BeforeCreate(tx *gorm.DB) error {
+	if o.Currency == "" {
+		o.Currency = DefaultCurrency
+	}
+	return checkCurrency(o.Currency)
+}
+
+// AfterFind menolak order yang nominalnya tersimpan dalam mata uang lain dan mengisi
+// mata uang semua nominal order beserta itemnya
+func (o *Order) AfterFind(tx *gorm.DB) error {
+	if err := checkCurrency(o.Currency); err != nil {
+		return fmt.Errorf("order %d: %w", o.ID, err)
+	}
+	for _, m := range []*Money{&o.Subtotal, &o.Discount, &o.Tax, &o.ShippingFee, &o.GrandTotal} {
+		m.withCurrency(o.Currency)
+	}
+	for i := range o.Items {
+		o.Items[i].Price.withCurrency(o.Currency)
+		o.Items[i].LineTotal.withCurrency(o.Currency)
+	}
+	return nil
 }
@@ -0,0 +1,38 @@
+package models
+
+import "testing"
+
+func TestOrderStatusCanTransitionTo(t *testing.T) {
+	statuses := []OrderStatus{
+		OrderStatusPending, OrderStatusPaid, OrderStatusPacked, OrderStatusShipped,
+		OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded,
+	}
+	allowed := map[OrderStatus][]OrderStatus{
+		OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+		OrderStatusPaid:      {OrderStatusPacked, OrderStatusCancelled, OrderStatusRefunded},
+		OrderStatusPacked:    {OrderStatusShipped, OrderStatusCancelled, OrderStatusRefunded},
+		OrderStatusShipped:   {OrderStatusDelivered},
+		OrderStatusDelivered: {OrderStatusRefunded},
+		// cancelled dan refunded adalah status akhir
+		OrderStatusCancelled: nil,
+		OrderStatusRefunded:  nil,
+	}
+
+	for _, from := range statuses {
+		want := map[OrderStatus]bool{}
+		for _, to := range allowed[from] {
+			want[to] = true
+		}
+		for _, to := range statuses {
+			if got := from.CanTransitionTo(to); got != want[to] {
+				t.Errorf("%s -> %s: got %v, want %v", from, to, got, want[to])
+			}
+		}
+		if from.CanTransitionTo("unknown") {
+			t.Errorf("%s -> unknown should be rejected", from)
+		}
+	}
+	if OrderStatus("unknown").CanTransitionTo(OrderStatusPaid) {
+		t.Error("unknown -> paid should be rejected")
+	}
+}
@@ -1,8 +1,57 @@
 package models
 
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
 type Product struct {
-	ID    uint `gorm:"primaryKey"`
-	Name  string
-	Price float64
-	Stock int
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:255;index"`
+	// SKU opsional; NULL untuk produk tanpa SKU sehingga unique index tidak bentrok
+	SKU   *string `gorm:"size:64;uniqueIndex"`
+	Price Money   `gorm:"index" swaggertype:"string"`
+	// Currency adalah mata uang Price dan harga varian, diisi DefaultCurrency saat dibuat
+	Currency string `gorm:"size:3;not null;default:''" example:"IDR"`
+	Stock    int    `gorm:"index"`
+	// ReorderThreshold memicu alert low-stock saat stok turun sampai nilai ini; 0 berarti nonaktif
+	ReorderThreshold int
+	Categories       []Category `gorm:"many2many:product_categories"`
+	Tags             []Tag      `gorm:"many2many:product_tags"`
+	Variants         []ProductVariant
+	// StockLevels adalah rincian stok per gudang; Stock adalah jumlah seluruhnya
+	StockLevels []StockLevel
+	// Images terurut berdasarkan Position
+	Images []ProductImage
+	// RatingAverage dan RatingCount adalah ringkasan review yang dipublikasikan
+	RatingAverage float64 `gorm:"type:decimal(3,2);not null;default:0"`
+	RatingCount   int     `gorm:"not null;default:0"`
+	// Version naik setiap kali produk diubah, dipakai untuk optimistic locking (ETag/If-Match)
+	Version uint `gorm:"not null;default:1"`
+	// DeletedAt terisi jika produk diarsipkan; produk arsip tidak tampil dan tidak bisa diorder
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// BeforeCreate mencatat mata uang harga produk baru
+func (p *Product) BeforeCreate(tx *gorm.DB) error {
+	if p.Currency == "" {
+		p.Currency = DefaultCurrency
+	}
+	return checkCurrency(p.Currency)
+}
+
+// AfterFind menolak produk yang harganya tersimpan dalam mata uang lain dan mengisi mata
+// uang harga produk beserta variannya
+func (p *Product) AfterFind(tx *gorm.DB) error {
+	if err := checkCurrency(p.Currency); err != nil {
+		return fmt.Errorf("product %d: %w", p.ID, err)
+	}
+	p.Price.withCurrency(p.Currency)
+	for i := range p.Variants {
+		if p.Variants[i].Price != nil {
+			p.Variants[i].Price.withCurrency(p.Currency)
+		}
+	}
+	return nil
 }
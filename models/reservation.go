@@ -0,0 +1,27 @@
+package models
+
+// ReservationStatus adalah status sebuah reservasi stok
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationConverted ReservationStatus = "converted" // sudah menjadi pengurangan stok order
+	ReservationReleased  ReservationStatus = "released"  // dilepas customer sebelum checkout
+	ReservationExpired   ReservationStatus = "expired"   // dilepas sweeper setelah TTL habis
+)
+
+// StockReservation menahan sejumlah stok di satu gudang untuk customer selama checkout.
+// Reservasi tidak mengubah stok; reservasi aktif yang belum kedaluwarsa dikurangkan dari
+// stok yang tersedia untuk customer lain.
+type StockReservation struct {
+	ID          uint `gorm:"primaryKey"`
+	UserID      uint `gorm:"index"`
+	ProductID   uint `gorm:"index:idx_reservation_location"`
+	VariantID   uint `gorm:"index:idx_reservation_location"` // 0 jika produk tanpa varian
+	WarehouseID uint `gorm:"index:idx_reservation_location"`
+	Quantity    int
+	Status      ReservationStatus `gorm:"size:20;index:idx_reservation_status_expires"`
+	OrderID     uint              // order hasil konversi reservasi
+	ExpiresAt   int64             `gorm:"index:idx_reservation_status_expires"`
+	CreatedAt   int64
+}
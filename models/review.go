@@ -0,0 +1,26 @@
+package models
+
+// ReviewStatus adalah status moderasi review
+type ReviewStatus string
+
+const (
+	// ReviewPublished tampil di produk dan dihitung di rating
+	ReviewPublished ReviewStatus = "published"
+	// ReviewHidden disembunyikan admin dan tidak dihitung di rating
+	ReviewHidden ReviewStatus = "hidden"
+)
+
+// Review adalah ulasan produk dari customer yang pernah menerima produk tersebut.
+// Setiap user hanya bisa memberi satu review per produk.
+type Review struct {
+	ID        uint `gorm:"primaryKey"`
+	ProductID uint `gorm:"uniqueIndex:idx_review_product_user;index:idx_review_product_status"`
+	UserID    uint `gorm:"uniqueIndex:idx_review_product_user"`
+	// OrderID adalah order delivered yang membuktikan pembelian
+	OrderID   uint
+	Rating    int          // 1-5
+	Body      string       `gorm:"size:2000"`
+	Status    ReviewStatus `gorm:"size:20;index:idx_review_product_status"`
+	CreatedAt int64
+	UpdatedAt int64
+}
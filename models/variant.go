@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Attributes adalah pasangan nama-nilai seperti {"size": "M", "color": "red"}
+// yang disimpan sebagai kolom JSON
+type Attributes map[string]string
+
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *Attributes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	}
+	return errors.New("scan attributes: unsupported type")
+}
+
+func (Attributes) GormDataType() string {
+	return "json"
+}
+
+// ProductVariant adalah varian produk (misal ukuran/warna) dengan SKU dan stok sendiri.
+// Jika produk memiliki varian, Product.Stock adalah jumlah stok seluruh variannya.
+type ProductVariant struct {
+	ID        uint       `gorm:"primaryKey"`
+	ProductID uint       `gorm:"index"`
+	SKU       string     `gorm:"size:64;uniqueIndex"`
+	Options   Attributes // nilai opsi varian, misal {"size": "M"}
+	Price     *Money     `swaggertype:"string"` // nil berarti mengikuti harga produk
+	Stock     int
+}
+
+// EffectivePrice mengembalikan harga varian atau harga produk jika varian tidak override
+func (v ProductVariant) EffectivePrice(product Product) Money {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return product.Price
+}
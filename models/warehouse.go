@@ -0,0 +1,24 @@
+package models
+
+// Warehouse adalah lokasi penyimpanan dan pengiriman stok
+type Warehouse struct {
+	ID   uint   `gorm:"primaryKey"`
+	Code string `gorm:"size:32;uniqueIndex"`
+	Name string `gorm:"size:255"`
+	// Priority menentukan urutan pemilihan gudang saat alokasi order, nilai kecil dipilih lebih dulu
+	Priority int `gorm:"index"`
+	// Active false berarti gudang tidak dipakai untuk alokasi order maupun stok masuk default
+	Active    bool
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// StockLevel adalah stok sebuah produk atau varian di satu gudang.
+// Jumlah Quantity semua gudang sama dengan Stock produk/varian.
+type StockLevel struct {
+	ID          uint `gorm:"primaryKey"`
+	WarehouseID uint `gorm:"uniqueIndex:idx_stock_level_location"`
+	ProductID   uint `gorm:"uniqueIndex:idx_stock_level_location;index"`
+	VariantID   uint `gorm:"uniqueIndex:idx_stock_level_location"` // 0 jika produk tanpa varian
+	Quantity    int
+}
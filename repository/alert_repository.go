@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+)
+
+type AlertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) *AlertRepository {
+	return &AlertRepository{db}
+}
+
+// FindPendingEvents mengembalikan event low-stock yang belum terkirim dan belum melewati
+// batas percobaan, terlama dulu
+func (r *AlertRepository) FindPendingEvents(maxAttempts, limit int) ([]models.LowStockEvent, error) {
+	var events []models.LowStockEvent
+	err := r.db.Where("delivered_at = 0 AND attempts < ?", maxAttempts).
+		Order("id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func (r *AlertRepository) MarkDelivered(event *models.LowStockEvent, at int64) error {
+	return r.db.Model(event).Updates(map[string]interface{}{
+		"delivered_at": at,
+		"attempts":     gorm.Expr("attempts + 1"),
+	}).Error
+}
+
+func (r *AlertRepository) MarkFailed(event *models.LowStockEvent, reason string) error {
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+	return r.db.Model(event).Updates(map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": reason,
+	}).Error
+}
+
+func (r *AlertRepository) CreateEmail(email *models.EmailOutbox) error {
+	return r.db.Create(email).Error
+}
+
+func (r *AlertRepository) WithTx(tx *gorm.DB) *AlertRepository {
+	return &AlertRepository{db: tx}
+}
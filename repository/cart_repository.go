@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) *CartRepository {
+	return &CartRepository{db}
+}
+
+func (r *CartRepository) FindByUser(userID uint) ([]models.CartItem, error) {
+	var items []models.CartItem
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&items).Error
+	return items, err
+}
+
+// FindByUserForUpdate sama seperti FindByUser tetapi mengunci baris keranjang sampai
+// transaksi selesai. Harus dipanggil di dalam transaksi.
+func (r *CartRepository) FindByUserForUpdate(userID uint) ([]models.CartItem, error) {
+	var items []models.CartItem
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).Order("id").Find(&items).Error
+	return items, err
+}
+
+// AddQuantity menambah quantity produk di keranjang, membuat baris baru jika belum ada
+func (r *CartRepository) AddQuantity(userID, productID, variantID uint, quantity int) error {
+	item := models.CartItem{UserID: userID, ProductID: productID, VariantID: variantID, Quantity: quantity}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "product_id"}, {Name: "variant_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity": gorm.Expr("quantity + ?", quantity),
+		}),
+	}).Create(&item).Error
+}
+
+// SetQuantity mengubah quantity produk di keranjang, false jika item tidak ditemukan
+func (r *CartRepository) SetQuantity(userID, productID, variantID uint, quantity int) (bool, error) {
+	result := r.db.Model(&models.CartItem{}).
+		Where("user_id = ? AND product_id = ? AND variant_id = ?", userID, productID, variantID).
+		Update("quantity", quantity)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete menghapus satu produk dari keranjang, false jika item tidak ditemukan
+func (r *CartRepository) Delete(userID, productID, variantID uint) (bool, error) {
+	result := r.db.Where("user_id = ? AND product_id = ? AND variant_id = ?", userID, productID, variantID).Delete(&models.CartItem{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *CartRepository) DeleteByIDs(userID uint, ids []uint) error {
+	return r.db.Where("user_id = ? AND id IN ?", userID, ids).Delete(&models.CartItem{}).Error
+}
+
+func (r *CartRepository) ClearByUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.CartItem{}).Error
+}
+
+func (r *CartRepository) WithTx(tx *gorm.DB) *CartRepository {
+	return &CartRepository{db: tx}
+}
+
+func (r *CartRepository) DB() *gorm.DB {
+	return r.db
+}
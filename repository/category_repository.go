@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db}
+}
+
+func (r *CategoryRepository) Create(category *models.Category) error {
+	return r.db.Create(category).Error
+}
+
+func (r *CategoryRepository) FindAll() ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Order("name").Find(&categories).Error
+	return categories, err
+}
+
+func (r *CategoryRepository) FindByID(id uint) (*models.Category, error) {
+	var category models.Category
+	err := r.db.First(&category, id).Error
+	return &category, err
+}
+
+func (r *CategoryRepository) FindByIDs(ids []uint) ([]models.Category, error) {
+	var categories []models.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+func (r *CategoryRepository) FindBySlug(slug string) (*models.Category, error) {
+	var category models.Category
+	err := r.db.Where("slug = ?", slug).First(&category).Error
+	return &category, err
+}
+
+func (r *CategoryRepository) CountChildren(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+func (r *CategoryRepository) Update(category *models.Category) error {
+	return r.db.Save(category).Error
+}
+
+// Delete menghapus kategori beserta relasinya ke produk
+func (r *CategoryRepository) Delete(category *models.Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_categories WHERE category_id = ?", category.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+}
+
+func (r *CategoryRepository) WithTx(tx *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: tx}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db}
+}
+
+// Insert menyimpan key baru, false jika key untuk user tersebut sudah ada
+func (r *IdempotencyRepository) Insert(key *models.IdempotencyKey) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *IdempotencyRepository) FindByUserKey(userID uint, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	err := r.db.Where("user_id = ? AND `key` = ?", userID, key).First(&record).Error
+	return &record, err
+}
+
+// TakeOver memperpanjang lease key yang belum selesai dan lease-nya sudah habis,
+// false jika key sudah selesai atau masih dipegang request lain
+func (r *IdempotencyRepository) TakeOver(key *models.IdempotencyKey, now, lockedUntil int64) (bool, error) {
+	result := r.db.Model(&models.IdempotencyKey{}).
+		Where("id = ? AND status_code = 0 AND locked_until = ? AND locked_until <= ?", key.ID, key.LockedUntil, now).
+		Update("locked_until", lockedUntil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SaveResponse menyimpan response key yang masih dipegang pemilik lease-nya, false jika
+// key sudah diambil alih request lain
+func (r *IdempotencyRepository) SaveResponse(key *models.IdempotencyKey, statusCode int, body []byte) (bool, error) {
+	result := r.db.Model(&models.IdempotencyKey{}).
+		Where("id = ? AND status_code = 0 AND locked_until = ?", key.ID, key.LockedUntil).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"response_body": body,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Release menghapus key yang belum selesai selama masih dipegang pemilik lease-nya
+func (r *IdempotencyRepository) Release(key *models.IdempotencyKey) error {
+	return r.db.Where("id = ? AND status_code = 0 AND locked_until = ?", key.ID, key.LockedUntil).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+func (r *IdempotencyRepository) DeleteExpiredByUser(userID uint, now int64) error {
+	return r.db.Where("user_id = ? AND expires_at <= ?", userID, now).Delete(&models.IdempotencyKey{}).Error
+}
+
+func (r *IdempotencyRepository) WithTx(tx *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: tx}
+}
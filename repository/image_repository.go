@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+)
+
+type ImageRepository struct {
+	db *gorm.DB
+}
+
+func NewImageRepository(db *gorm.DB) *ImageRepository {
+	return &ImageRepository{db}
+}
+
+func (r *ImageRepository) Create(image *models.ProductImage) error {
+	return r.db.Create(image).Error
+}
+
+// FindByProduct mengembalikan gambar produk sesuai urutan tampil
+func (r *ImageRepository) FindByProduct(productID uint) ([]models.ProductImage, error) {
+	images := []models.ProductImage{}
+	err := r.db.Where("product_id = ?", productID).Order("position").Order("id").Find(&images).Error
+	return images, err
+}
+
+func (r *ImageRepository) FindByID(productID, imageID uint) (*models.ProductImage, error) {
+	var image models.ProductImage
+	err := r.db.Where("product_id = ?", productID).First(&image, imageID).Error
+	return &image, err
+}
+
+// NextPosition mengembalikan posisi untuk gambar baru, yaitu setelah gambar terakhir
+func (r *ImageRepository) NextPosition(productID uint) (int, error) {
+	var position int
+	err := r.db.Model(&models.ProductImage{}).Where("product_id = ?", productID).
+		Select("COALESCE(MAX(position), 0) + 1").Scan(&position).Error
+	return position, err
+}
+
+func (r *ImageRepository) Count(productID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ProductImage{}).Where("product_id = ?", productID).Count(&count).Error
+	return count, err
+}
+
+func (r *ImageRepository) UpdatePosition(imageID uint, position int) error {
+	return r.db.Model(&models.ProductImage{}).Where("id = ?", imageID).Update("position", position).Error
+}
+
+// SetPrimary menjadikan imageID satu-satunya gambar utama produk
+func (r *ImageRepository) SetPrimary(productID, imageID uint) error {
+	return r.db.Model(&models.ProductImage{}).Where("product_id = ?", productID).
+		Update("is_primary", gorm.Expr("id = ?", imageID)).Error
+}
+
+func (r *ImageRepository) Delete(image *models.ProductImage) error {
+	return r.db.Delete(image).Error
+}
+
+func (r *ImageRepository) WithTx(tx *gorm.DB) *ImageRepository {
+	return &ImageRepository{db: tx}
+}
+
+func (r *ImageRepository) DB() *gorm.DB {
+	return r.db
+}
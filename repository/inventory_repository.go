@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNoActiveWarehouse dikembalikan saat stok masuk tanpa gudang tetapi tidak ada gudang aktif
+	ErrNoActiveWarehouse = errors.New("no active warehouse")
+	// ErrProductNotFound dan ErrVariantNotFound dikembalikan Apply saat produk atau varian
+	// movement tidak ada, sehingga transaksi pemanggil harus dibatalkan
+	ErrProductNotFound = errors.New("product not found")
+	ErrVariantNotFound = errors.New("product variant not found")
+)
+
+// StockMismatch adalah produk, varian atau stok gudang yang tidak sama dengan jumlah ledger
+type StockMismatch struct {
+	ProductID   uint
+	VariantID   *uint
+	WarehouseID *uint
+	Stock       int64
+	LedgerStock int64
+}
+
+// InventoryRepository adalah satu-satunya jalur untuk mengubah stok produk dan varian.
+// Setiap perubahan stok dicatat ke ledger inventory_movements dalam query yang sama.
+type InventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) *InventoryRepository {
+	return &InventoryRepository{db}
+}
+
+// Apply menerapkan movement ke stok gudang, stok varian dan stok agregat produk, lalu
+// mencatatnya di ledger. Pengurangan stok bersifat kondisional sehingga stok gudang tidak
+// pernah negatif; mengembalikan false jika stok di gudang tersebut kurang. Jika produk
+// atau varian tidak ada, stok gudang mungkin sudah terlanjur diubah sehingga Apply
+// mengembalikan ErrProductNotFound/ErrVariantNotFound dan transaksi harus dibatalkan.
+// WarehouseID 0 berarti gudang default (gudang aktif dengan prioritas tertinggi). Harus
+// dipanggil di dalam transaksi.
+//
+// Baris diubah dengan urutan stock_levels → product_variants → products. Pemanggil yang
+// mengunci baris lebih dulu harus mengikuti urutan yang sama (lihat LockLevels) agar
+// tidak deadlock dengan checkout.
+func (r *InventoryRepository) Apply(movement *models.InventoryMovement) (bool, error) {
+	if movement.Delta == 0 {
+		return true, nil
+	}
+	if movement.WarehouseID == 0 {
+		warehouse, err := NewWarehouseRepository(r.db).FindDefault()
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, ErrNoActiveWarehouse
+			}
+			return false, err
+		}
+		movement.WarehouseID = warehouse.ID
+	}
+	var variantID uint
+	if movement.VariantID != nil {
+		variantID = *movement.VariantID
+	}
+	if movement.Delta < 0 {
+		result := r.db.Model(&models.StockLevel{}).
+			Where("warehouse_id = ? AND product_id = ? AND variant_id = ? AND quantity >= ?",
+				movement.WarehouseID, movement.ProductID, variantID, -movement.Delta).
+			Update("quantity", gorm.Expr("quantity + ?", movement.Delta))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return false, result.Error
+		}
+	} else {
+		level := models.StockLevel{
+			WarehouseID: movement.WarehouseID,
+			ProductID:   movement.ProductID,
+			VariantID:   variantID,
+			Quantity:    movement.Delta,
+		}
+		if err := r.db.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "warehouse_id"}, {Name: "product_id"}, {Name: "variant_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"quantity": gorm.Expr("quantity + ?", movement.Delta),
+			}),
+		}).Create(&level).Error; err != nil {
+			return false, err
+		}
+	}
+	if movement.VariantID != nil {
+		result := r.db.Model(&models.ProductVariant{}).
+			Where("id = ? AND product_id = ?", variantID, movement.ProductID).
+			Update("stock", gorm.Expr("stock + ?", movement.Delta))
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return false, ErrVariantNotFound
+		}
+	}
+	// produk arsip tetap bisa menerima stok kembali, misal dari order yang dibatalkan
+	result := r.db.Unscoped().Model(&models.Product{}).Where("id = ?", movement.ProductID).
+		Update("stock", gorm.Expr("stock + ?", movement.Delta))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, ErrProductNotFound
+	}
+	if err := r.db.Create(movement).Error; err != nil {
+		return false, err
+	}
+	if movement.Delta < 0 {
+		if err := r.recordLowStock(movement); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// recordLowStock mencatat LowStockEvent jika movement membuat stok produk turun dari
+// di atas ReorderThreshold menjadi sama dengan atau di bawahnya. Event ditulis di
+// transaksi yang sama sehingga hanya terkirim jika perubahan stok ikut tersimpan.
+func (r *InventoryRepository) recordLowStock(movement *models.InventoryMovement) error {
+	var product models.Product
+	if err := r.db.Unscoped().Select("id", "name", "stock", "reorder_threshold").
+		First(&product, movement.ProductID).Error; err != nil {
+		return err
+	}
+	threshold := product.ReorderThreshold
+	before := product.Stock - movement.Delta
+	if threshold <= 0 || product.Stock > threshold || before <= threshold {
+		return nil
+	}
+	return r.db.Create(&models.LowStockEvent{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		Stock:       product.Stock,
+		Threshold:   threshold,
+	}).Error
+}
+
+// FindLowStock mengembalikan produk aktif yang stoknya sudah mencapai ReorderThreshold,
+// stok paling kritis dulu
+func (r *InventoryRepository) FindLowStock(offset, limit int) ([]models.Product, int64, error) {
+	tx := r.db.Model(&models.Product{}).Where("reorder_threshold > 0 AND stock <= reorder_threshold")
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	products := make([]models.Product, 0, limit)
+	err := tx.Order("stock - reorder_threshold").Order("id").
+		Offset(offset).Limit(limit).Find(&products).Error
+	return products, total, err
+}
+
+// FindLevelsForUpdate mengambil stok produk/varian di gudang aktif dengan row lock,
+// terurut berdasarkan prioritas gudang. variantID 0 untuk produk tanpa varian.
+// Hanya baris stock_levels yang dikunci (lewat LockLevels); gudang dibaca tanpa lock
+// sehingga checkout tidak menahan perubahan data gudang.
+func (r *InventoryRepository) FindLevelsForUpdate(productID, variantID uint) ([]models.StockLevel, error) {
+	levels, err := r.LockLevels(productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	return r.activeLevels(levels)
+}
+
+// FindActiveLevels sama seperti FindLevelsForUpdate tetapi tanpa row lock, untuk
+// menampilkan ketersediaan stok
+func (r *InventoryRepository) FindActiveLevels(productID, variantID uint) ([]models.StockLevel, error) {
+	var levels []models.StockLevel
+	if err := r.db.Where("product_id = ? AND variant_id = ?", productID, variantID).
+		Find(&levels).Error; err != nil {
+		return nil, err
+	}
+	return r.activeLevels(levels)
+}
+
+// activeLevels menyaring stok di gudang aktif dan mengurutkannya berdasarkan prioritas gudang
+func (r *InventoryRepository) activeLevels(levels []models.StockLevel) ([]models.StockLevel, error) {
+	if len(levels) == 0 {
+		return levels, nil
+	}
+	byWarehouse := make(map[uint]models.StockLevel, len(levels))
+	warehouseIDs := make([]uint, 0, len(levels))
+	for _, level := range levels {
+		byWarehouse[level.WarehouseID] = level
+		warehouseIDs = append(warehouseIDs, level.WarehouseID)
+	}
+	var warehouses []models.Warehouse
+	if err := r.db.Select("id").Where("id IN ? AND active = ?", warehouseIDs, true).
+		Order("priority").Order("id").Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+	active := make([]models.StockLevel, 0, len(warehouses))
+	for _, warehouse := range warehouses {
+		active = append(active, byWarehouse[warehouse.ID])
+	}
+	return active, nil
+}
+
+// LockLevels mengunci stok produk/varian di semua gudang, termasuk gudang nonaktif.
+// Baris dikunci terurut berdasarkan id sehingga dua transaksi selalu mengambil lock
+// dengan urutan yang sama. Panggil sebelum mengunci varian atau produk.
+func (r *InventoryRepository) LockLevels(productID, variantID uint) ([]models.StockLevel, error) {
+	var levels []models.StockLevel
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND variant_id = ?", productID, variantID).
+		Order("id").Find(&levels).Error
+	return levels, err
+}
+
+// LockProductLevels mengunci stok produk beserta semua variannya di semua gudang,
+// terurut berdasarkan id
+func (r *InventoryRepository) LockProductLevels(productID uint) error {
+	var ids []uint
+	return r.db.Model(&models.StockLevel{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", productID).Order("id").Pluck("id", &ids).Error
+}
+
+// DeleteLevels menghapus baris stok gudang milik varian yang dihapus
+func (r *InventoryRepository) DeleteLevels(productID, variantID uint) error {
+	return r.db.Where("product_id = ? AND variant_id = ?", productID, variantID).
+		Delete(&models.StockLevel{}).Error
+}
+
+// FindByProduct mengembalikan movement sebuah produk (terbaru dulu) beserta jumlah totalnya
+func (r *InventoryRepository) FindByProduct(productID uint, offset, limit int) ([]models.InventoryMovement, int64, error) {
+	tx := r.db.Model(&models.InventoryMovement{}).Where("product_id = ?", productID)
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	movements := make([]models.InventoryMovement, 0, limit)
+	err := tx.Order("id DESC").Offset(offset).Limit(limit).Find(&movements).Error
+	return movements, total, err
+}
+
+// FindMismatches membandingkan stok produk, varian dan stok per gudang dengan jumlah
+// delta di ledger.
+// productID 0 berarti memeriksa semua produk, termasuk produk arsip.
+func (r *InventoryRepository) FindMismatches(productID uint) ([]StockMismatch, error) {
+	var products []StockMismatch
+	productQuery := `SELECT p.id AS product_id, NULL AS variant_id, p.stock AS stock,
+			COALESCE(SUM(m.delta), 0) AS ledger_stock
+		FROM products p LEFT JOIN inventory_movements m ON m.product_id = p.id
+		WHERE ? = 0 OR p.id = ?
+		GROUP BY p.id, p.stock
+		HAVING p.stock <> COALESCE(SUM(m.delta), 0)`
+	if err := r.db.Raw(productQuery, productID, productID).Scan(&products).Error; err != nil {
+		return nil, err
+	}
+	var variants []StockMismatch
+	variantQuery := `SELECT v.product_id AS product_id, v.id AS variant_id, v.stock AS stock,
+			COALESCE(SUM(m.delta), 0) AS ledger_stock
+		FROM product_variants v LEFT JOIN inventory_movements m ON m.variant_id = v.id
+		WHERE ? = 0 OR v.product_id = ?
+		GROUP BY v.id, v.product_id, v.stock
+		HAVING v.stock <> COALESCE(SUM(m.delta), 0)`
+	if err := r.db.Raw(variantQuery, productID, productID).Scan(&variants).Error; err != nil {
+		return nil, err
+	}
+	var levels []StockMismatch
+	levelQuery := `SELECT l.product_id AS product_id, NULLIF(l.variant_id, 0) AS variant_id,
+			l.warehouse_id AS warehouse_id, l.quantity AS stock, COALESCE(SUM(m.delta), 0) AS ledger_stock
+		FROM stock_levels l LEFT JOIN inventory_movements m ON m.warehouse_id = l.warehouse_id
+			AND m.product_id = l.product_id AND COALESCE(m.variant_id, 0) = l.variant_id
+		WHERE ? = 0 OR l.product_id = ?
+		GROUP BY l.id, l.product_id, l.variant_id, l.warehouse_id, l.quantity
+		HAVING l.quantity <> COALESCE(SUM(m.delta), 0)`
+	if err := r.db.Raw(levelQuery, productID, productID).Scan(&levels).Error; err != nil {
+		return nil, err
+	}
+	return append(append(products, variants...), levels...), nil
+}
+
+func (r *InventoryRepository) WithTx(tx *gorm.DB) *InventoryRepository {
+	return &InventoryRepository{db: tx}
+}
+
+func (r *InventoryRepository) DB() *gorm.DB {
+	return r.db
+}
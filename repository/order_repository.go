@@ -3,8 +3,24 @@ package repository
 import (
 	"github.com/wahyuutomoputra/order-management/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// OrderQuery adalah kriteria pencarian order untuk admin. Nilai nol berarti tanpa filter.
+type OrderQuery struct {
+	UserID    uint
+	Status    models.OrderStatus
+	From      int64 // unix detik, inklusif
+	To        int64 // unix detik, inklusif
+	ProductID uint
+	MinTotal  *int64 // minor unit
+	MaxTotal  *int64 // minor unit
+	SortBy    string // nama kolom yang sudah divalidasi
+	SortDesc  bool
+	Offset    int
+	Limit     int
+}
+
 type OrderRepository struct {
 	db *gorm.DB
 }
@@ -17,14 +33,111 @@ func (r *OrderRepository) Create(order *models.Order) error {
 	return r.db.Create(order).Error
 }
 
+// CreateItems menyimpan item order setelah header order dibuat
+func (r *OrderRepository) CreateItems(items []models.OrderItem) error {
+	return r.db.Create(&items).Error
+}
+
+// UpdateTotals menyimpan subtotal, diskon, pajak, ongkir dan grand total order
+func (r *OrderRepository) UpdateTotals(order *models.Order) error {
+	return r.db.Model(order).
+		Select("Subtotal", "Discount", "Tax", "ShippingFee", "GrandTotal").
+		Updates(order).Error
+}
+
 func (r *OrderRepository) FindByUser(userID uint) ([]models.Order, error) {
 	var orders []models.Order
 	err := r.db.Preload("Items").Where("user_id = ?", userID).Find(&orders).Error
 	return orders, err
 }
 
-func (r *OrderRepository) UpdateProduct(product *models.Product) error {
-	return r.db.Save(product).Error
+// FindAll mengembalikan order sesuai query beserta jumlah total order yang cocok
+func (r *OrderRepository) FindAll(q OrderQuery) ([]models.Order, int64, error) {
+	tx := r.db.Model(&models.Order{})
+	if q.UserID != 0 {
+		tx = tx.Where("user_id = ?", q.UserID)
+	}
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+	if q.From != 0 {
+		tx = tx.Where("created_at >= ?", q.From)
+	}
+	if q.To != 0 {
+		tx = tx.Where("created_at <= ?", q.To)
+	}
+	if q.ProductID != 0 {
+		tx = tx.Where("id IN (?)", r.db.Model(&models.OrderItem{}).Select("order_id").Where("product_id = ?", q.ProductID))
+	}
+	if q.MinTotal != nil {
+		tx = tx.Where("grand_total >= ?", *q.MinTotal)
+	}
+	if q.MaxTotal != nil {
+		tx = tx.Where("grand_total <= ?", *q.MaxTotal)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	orders := make([]models.Order, 0, q.Limit)
+	err := tx.Preload("Items").
+		Order(clause.OrderByColumn{Column: clause.Column{Name: q.SortBy}, Desc: q.SortDesc}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: q.SortDesc}).
+		Offset(q.Offset).Limit(q.Limit).
+		Find(&orders).Error
+	return orders, total, err
+}
+
+func (r *OrderRepository) FindByID(id uint) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Preload("Items").First(&order, id).Error
+	return &order, err
+}
+
+// FindByIDForUpdate mengambil order dengan row lock, dipakai di dalam transaksi
+func (r *OrderRepository) FindByIDForUpdate(id uint) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&order, id).Error
+	return &order, err
+}
+
+func (r *OrderRepository) UpdateStatus(order *models.Order, status models.OrderStatus) error {
+	return r.db.Model(order).Update("status", status).Error
+}
+
+// FindDeliveredOrderWithProduct mengembalikan id order delivered terbaru milik user yang
+// berisi produk tersebut; gorm.ErrRecordNotFound jika user belum pernah menerimanya
+func (r *OrderRepository) FindDeliveredOrderWithProduct(userID, productID uint) (uint, error) {
+	var order models.Order
+	err := r.db.Select("orders.id").
+		Joins("JOIN order_items ON order_items.order_id = orders.id").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.product_id = ?",
+			userID, models.OrderStatusDelivered, productID).
+		Order("orders.id DESC").First(&order).Error
+	return order.ID, err
+}
+
+func (r *OrderRepository) CreateStatusHistory(history *models.OrderStatusHistory) error {
+	return r.db.Create(history).Error
+}
+
+func (r *OrderRepository) FindStatusHistory(orderID uint) ([]models.OrderStatusHistory, error) {
+	var histories []models.OrderStatusHistory
+	err := r.db.Where("order_id = ?", orderID).Order("id").Find(&histories).Error
+	return histories, err
+}
+
+func (r *OrderRepository) FindVariantByID(productID, variantID uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.Where("product_id = ?", productID).First(&variant, variantID).Error
+	return &variant, err
+}
+
+func (r *OrderRepository) CountVariants(productID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ProductVariant{}).Where("product_id = ?", productID).Count(&count).Error
+	return count, err
 }
 
 func (r *OrderRepository) FindProductByID(id uint) (*models.Product, error) {
@@ -1,10 +1,28 @@
 package repository
 
 import (
+	"strings"
+
 	"github.com/wahyuutomoputra/order-management/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ProductQuery adalah kriteria pencarian produk. Nilai nol berarti tanpa filter.
+type ProductQuery struct {
+	Name     string // substring nama produk
+	MinPrice *int64 // minor unit
+	MaxPrice *int64 // minor unit
+	InStock  bool
+	// CategoryIDs berisi kategori terpilih beserta seluruh turunannya
+	CategoryIDs []uint
+	Tag         string
+	SortBy      string // nama kolom yang sudah divalidasi
+	SortDesc    bool
+	Offset      int
+	Limit       int
+}
+
 type ProductRepository struct {
 	db *gorm.DB
 }
@@ -23,16 +41,315 @@ func (r *ProductRepository) FindAll() ([]models.Product, error) {
 	return products, err
 }
 
+// FindPage mengembalikan produk sesuai query beserta jumlah total produk yang cocok
+func (r *ProductRepository) FindPage(q ProductQuery) ([]models.Product, int64, error) {
+	tx := r.db.Model(&models.Product{})
+	if q.Name != "" {
+		tx = tx.Where("name LIKE ?", "%"+escapeLike(q.Name)+"%")
+	}
+	if q.MinPrice != nil {
+		tx = tx.Where("price >= ?", *q.MinPrice)
+	}
+	if q.MaxPrice != nil {
+		tx = tx.Where("price <= ?", *q.MaxPrice)
+	}
+	if q.InStock {
+		tx = tx.Where("id IN (?)", activeStockQuery(r.db).Where("l.quantity > 0").Select("l.product_id"))
+	}
+	if len(q.CategoryIDs) > 0 {
+		tx = tx.Where("id IN (?)", r.db.Table("product_categories").Select("product_id").Where("category_id IN ?", q.CategoryIDs))
+	}
+	if q.Tag != "" {
+		tx = tx.Where("id IN (?)", r.db.Table("product_tags").Select("product_tags.product_id").
+			Joins("JOIN tags ON tags.id = product_tags.tag_id").Where("tags.name = ?", q.Tag))
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	products := make([]models.Product, 0, q.Limit)
+	err := tx.Preload("Categories").Preload("Tags").Preload("Variants").Preload("Images", orderImages).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: q.SortBy}, Desc: q.SortDesc}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: q.SortDesc}).
+		Offset(q.Offset).Limit(q.Limit).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, r.fillActiveStock(products)
+}
+
+// escapeLike meng-escape karakter wildcard LIKE agar dicari secara literal
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 func (r *ProductRepository) FindByID(id uint) (*models.Product, error) {
 	var product models.Product
-	err := r.db.First(&product, id).Error
+	err := r.db.Preload("Categories").Preload("Tags").Preload("Variants").Preload("StockLevels").
+		Preload("Images", orderImages).First(&product, id).Error
+	if err != nil {
+		return &product, err
+	}
+	return &product, r.FillActiveStock(&product)
+}
+
+// activeStockQuery memilih stok gudang (alias l) yang berada di gudang aktif
+func activeStockQuery(db *gorm.DB) *gorm.DB {
+	return db.Table("stock_levels l").Joins("JOIN warehouses w ON w.id = l.warehouse_id").Where("w.active = ?", true)
+}
+
+// FillActiveStock mengganti Stock produk dan variannya dengan jumlah stok di gudang aktif,
+// yaitu stok yang bisa dialokasikan ke order. Kolom stock tetap berisi stok di semua
+// gudang (termasuk gudang nonaktif) sesuai ledger dan dipakai untuk alert low-stock.
+func (r *ProductRepository) FillActiveStock(products ...*models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	var rows []struct {
+		ProductID uint
+		VariantID uint
+		Quantity  int
+	}
+	if err := activeStockQuery(r.db).Select("l.product_id, l.variant_id, SUM(l.quantity) AS quantity").
+		Where("l.product_id IN ?", ids).Group("l.product_id, l.variant_id").Scan(&rows).Error; err != nil {
+		return err
+	}
+	productStock := make(map[uint]int, len(products))
+	variantStock := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		productStock[row.ProductID] += row.Quantity
+		if row.VariantID != 0 {
+			variantStock[row.VariantID] = row.Quantity
+		}
+	}
+	for _, product := range products {
+		product.Stock = productStock[product.ID]
+		for i := range product.Variants {
+			product.Variants[i].Stock = variantStock[product.Variants[i].ID]
+		}
+	}
+	return nil
+}
+
+func (r *ProductRepository) fillActiveStock(products []models.Product) error {
+	pointers := make([]*models.Product, len(products))
+	for i := range products {
+		pointers[i] = &products[i]
+	}
+	return r.FillActiveStock(pointers...)
+}
+
+// orderImages mengurutkan gambar yang di-preload sesuai urutan tampil
+func orderImages(db *gorm.DB) *gorm.DB {
+	return db.Order("position").Order("id")
+}
+
+// FindForUpdate mengambil produk beserta variannya dengan row lock pada baris produk
+func (r *ProductRepository) FindForUpdate(id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Variants").First(&product, id).Error
 	return &product, err
 }
 
-func (r *ProductRepository) Update(product *models.Product) error {
-	return r.db.Save(product).Error
+// Update menyimpan perubahan produk hanya jika versinya masih sama dengan product.Version,
+// lalu menaikkan versi. Mengembalikan false jika produk sudah diubah pihak lain.
+// Stok tidak ikut disimpan karena hanya boleh diubah lewat InventoryRepository.
+func (r *ProductRepository) Update(product *models.Product) (bool, error) {
+	next := *product
+	next.Version++
+	result := r.db.Model(&models.Product{ID: product.ID}).Where("version = ?", product.Version).
+		Select("*").Omit(clause.Associations, "ID", "Stock", "RatingAverage", "RatingCount", "DeletedAt").Updates(&next)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	product.Version = next.Version
+	return true, nil
 }
 
+// Delete mengarsipkan produk (soft delete); varian dan relasinya tetap disimpan
 func (r *ProductRepository) Delete(product *models.Product) error {
 	return r.db.Delete(product).Error
 }
+
+// FindArchived mengembalikan produk yang sudah diarsipkan beserta jumlah totalnya
+func (r *ProductRepository) FindArchived(offset, limit int) ([]models.Product, int64, error) {
+	tx := r.db.Unscoped().Model(&models.Product{}).Where("deleted_at IS NOT NULL")
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	products := make([]models.Product, 0, limit)
+	err := tx.Preload("Variants").Order("deleted_at DESC").Order("id DESC").
+		Offset(offset).Limit(limit).Find(&products).Error
+	return products, total, err
+}
+
+func (r *ProductRepository) FindArchivedByID(id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&product, id).Error
+	return &product, err
+}
+
+// FindByIDUnscoped mengambil produk termasuk yang sudah diarsipkan
+func (r *ProductRepository) FindByIDUnscoped(id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Unscoped().First(&product, id).Error
+	return &product, err
+}
+
+func (r *ProductRepository) Restore(product *models.Product) error {
+	return r.db.Unscoped().Model(product).Update("deleted_at", nil).Error
+}
+
+// CountOrderReferences menghitung item order yang mereferensikan produk
+func (r *ProductRepository) CountOrderReferences(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OrderItem{}).Where("product_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// Purge menghapus produk secara permanen beserta varian, stok gudang, relasi kategori/tag
+// dan item keranjangnya
+func (r *ProductRepository) Purge(product *models.Product) error {
+	if err := r.db.Where("product_id = ?", product.ID).Delete(&models.CartItem{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("product_id = ?", product.ID).Delete(&models.Review{}).Error; err != nil {
+		return err
+	}
+	return r.db.Unscoped().Select("Categories", "Tags", "Variants", "StockLevels", "Images").Delete(product).Error
+}
+
+// FindImageKeys mengembalikan storage key semua gambar produk
+func (r *ProductRepository) FindImageKeys(id uint) ([]string, error) {
+	var keys []string
+	err := r.db.Model(&models.ProductImage{}).Where("product_id = ?", id).Pluck("storage_key", &keys).Error
+	return keys, err
+}
+
+func (r *ProductRepository) FindByIDs(ids []uint) ([]models.Product, error) {
+	var products []models.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+// ReplaceCategories mengganti seluruh kategori produk
+func (r *ProductRepository) ReplaceCategories(product *models.Product, categories []models.Category) error {
+	return r.db.Model(product).Association("Categories").Replace(categories)
+}
+
+// ReplaceTags mengganti seluruh tag produk
+func (r *ProductRepository) ReplaceTags(product *models.Product, tags []models.Tag) error {
+	return r.db.Model(product).Association("Tags").Replace(tags)
+}
+
+func (r *ProductRepository) CreateVariant(variant *models.ProductVariant) error {
+	return r.db.Create(variant).Error
+}
+
+// FindVariantForUpdate mengambil varian milik produk dengan row lock
+func (r *ProductRepository) FindVariantForUpdate(productID, variantID uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", productID).First(&variant, variantID).Error
+	return &variant, err
+}
+
+func (r *ProductRepository) FindVariantsByIDs(ids []uint) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	if len(ids) == 0 {
+		return variants, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&variants).Error
+	return variants, err
+}
+
+// FindWithSKUInBatches membaca semua produk aktif yang punya SKU beserta kategori dan
+// tag-nya per batch, terurut berdasarkan id, agar katalog besar bisa diekspor tanpa
+// dimuat sekaligus
+func (r *ProductRepository) FindWithSKUInBatches(batchSize int, fn func(products []models.Product) error) error {
+	var products []models.Product
+	return r.db.Preload("Categories").Preload("Tags").Where("sku IS NOT NULL AND sku <> ''").Order("id").
+		FindInBatches(&products, batchSize, func(_ *gorm.DB, _ int) error {
+			return fn(products)
+		}).Error
+}
+
+// CountWithoutSKU menghitung produk aktif yang tidak punya SKU
+func (r *ProductRepository) CountWithoutSKU() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Product{}).Where("sku IS NULL OR sku = ''").Count(&count).Error
+	return count, err
+}
+
+// SearchFullText mencari produk aktif dengan index FULLTEXT dalam boolean mode: produk
+// yang nama/SKU-nya cocok atau yang punya tag cocok. Urutan berdasarkan relevansi nama
+// dan SKU, sehingga produk yang hanya cocok lewat tag berada di belakang. expr harus
+// sudah bersih dari operator yang tidak diinginkan.
+func (r *ProductRepository) SearchFullText(expr string, limit int) ([]models.Product, error) {
+	var products []models.Product
+	tagged := r.db.Table("product_tags").Select("product_tags.product_id").
+		Joins("JOIN tags ON tags.id = product_tags.tag_id").
+		Where("MATCH(tags.name) AGAINST (? IN BOOLEAN MODE)", expr)
+	err := r.db.Preload("Tags").Preload("Images", orderImages).
+		Where("MATCH(name, sku) AGAINST (? IN BOOLEAN MODE) OR id IN (?)", expr, tagged).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "MATCH(name, sku) AGAINST (? IN BOOLEAN MODE) DESC, id",
+			Vars:               []interface{}{expr},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, r.fillActiveStock(products)
+}
+
+// FindSearchable membaca semua produk aktif beserta tag dan gambarnya untuk index pencarian in-process
+func (r *ProductRepository) FindSearchable() ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Preload("Tags").Preload("Images", orderImages).Order("id").Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, r.fillActiveStock(products)
+}
+
+// FindBySKU mencari produk berdasarkan SKU, termasuk produk yang sudah diarsipkan
+func (r *ProductRepository) FindBySKU(sku string) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Unscoped().Where("sku = ?", sku).First(&product).Error
+	return &product, err
+}
+
+func (r *ProductRepository) FindVariantBySKU(sku string) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.Where("sku = ?", sku).First(&variant).Error
+	return &variant, err
+}
+
+// UpdateVariant menyimpan perubahan varian tanpa stok; stok diubah lewat InventoryRepository
+func (r *ProductRepository) UpdateVariant(variant *models.ProductVariant) error {
+	return r.db.Omit("Stock").Save(variant).Error
+}
+
+func (r *ProductRepository) DeleteVariant(variant *models.ProductVariant) error {
+	return r.db.Delete(variant).Error
+}
+
+func (r *ProductRepository) WithTx(tx *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: tx}
+}
+
+func (r *ProductRepository) DB() *gorm.DB {
+	return r.db
+}
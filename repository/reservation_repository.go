@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db}
+}
+
+func (r *ReservationRepository) Create(reservations []models.StockReservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	return r.db.Create(&reservations).Error
+}
+
+// FindActiveByUser mengembalikan reservasi aktif milik user yang belum kedaluwarsa
+func (r *ReservationRepository) FindActiveByUser(userID uint, now int64) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	err := r.db.Where("user_id = ? AND status = ? AND expires_at > ?", userID, models.ReservationActive, now).
+		Order("id").Find(&reservations).Error
+	return reservations, err
+}
+
+// SumHeldByOthers menjumlahkan reservasi aktif milik user lain per gudang untuk satu
+// produk/varian. variantID 0 untuk produk tanpa varian.
+func (r *ReservationRepository) SumHeldByOthers(productID, variantID, userID uint, now int64) (map[uint]int, error) {
+	return sumHeldByOthers(r.db, productID, variantID, userID, now)
+}
+
+// SumHeldByOthersLocked sama seperti SumHeldByOthers tetapi memakai locking read (FOR SHARE)
+// sehingga di dalam transaksi REPEATABLE READ yang dibaca adalah reservasi terbaru yang
+// sudah di-commit, bukan snapshot dari awal transaksi
+func (r *ReservationRepository) SumHeldByOthersLocked(productID, variantID, userID uint, now int64) (map[uint]int, error) {
+	return sumHeldByOthers(r.db.Clauses(clause.Locking{Strength: "SHARE"}), productID, variantID, userID, now)
+}
+
+func sumHeldByOthers(db *gorm.DB, productID, variantID, userID uint, now int64) (map[uint]int, error) {
+	var rows []struct {
+		WarehouseID uint
+		Quantity    int
+	}
+	err := db.Model(&models.StockReservation{}).
+		Select("warehouse_id, SUM(quantity) AS quantity").
+		Where("product_id = ? AND variant_id = ? AND user_id <> ? AND status = ? AND expires_at > ?",
+			productID, variantID, userID, models.ReservationActive, now).
+		Group("warehouse_id").Scan(&rows).Error
+	held := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		held[row.WarehouseID] = row.Quantity
+	}
+	return held, err
+}
+
+// MarkConverted menandai reservasi aktif user untuk produk/varian tersebut sebagai
+// sudah dikonversi menjadi order
+func (r *ReservationRepository) MarkConverted(userID, productID, variantID, orderID uint) error {
+	return r.db.Model(&models.StockReservation{}).
+		Where("user_id = ? AND product_id = ? AND variant_id = ? AND status = ?",
+			userID, productID, variantID, models.ReservationActive).
+		Updates(map[string]interface{}{"status": models.ReservationConverted, "order_id": orderID}).Error
+}
+
+// ReleaseByUser melepas semua reservasi aktif milik user
+func (r *ReservationRepository) ReleaseByUser(userID uint) error {
+	return r.db.Model(&models.StockReservation{}).
+		Where("user_id = ? AND status = ?", userID, models.ReservationActive).
+		Update("status", models.ReservationReleased).Error
+}
+
+// ExpireBefore menandai reservasi aktif yang TTL-nya sudah habis sebagai expired
+// dan mengembalikan jumlah reservasi yang dilepas
+func (r *ReservationRepository) ExpireBefore(now int64) (int64, error) {
+	result := r.db.Model(&models.StockReservation{}).
+		Where("status = ? AND expires_at <= ?", models.ReservationActive, now).
+		Update("status", models.ReservationExpired)
+	return result.RowsAffected, result.Error
+}
+
+func (r *ReservationRepository) WithTx(tx *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: tx}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+)
+
+// ReviewQuery adalah kriteria daftar review. Nilai nol berarti tanpa filter.
+type ReviewQuery struct {
+	ProductID uint
+	Status    models.ReviewStatus
+	Offset    int
+	Limit     int
+}
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db}
+}
+
+func (r *ReviewRepository) Create(review *models.Review) error {
+	return r.db.Create(review).Error
+}
+
+func (r *ReviewRepository) FindByID(id uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.First(&review, id).Error
+	return &review, err
+}
+
+// ExistsForUser mengecek apakah user sudah pernah mereview produk
+func (r *ReviewRepository) ExistsForUser(productID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Review{}).Where("product_id = ? AND user_id = ?", productID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// FindPage mengembalikan review sesuai query (terbaru dulu) beserta jumlah totalnya
+func (r *ReviewRepository) FindPage(q ReviewQuery) ([]models.Review, int64, error) {
+	tx := r.db.Model(&models.Review{})
+	if q.ProductID != 0 {
+		tx = tx.Where("product_id = ?", q.ProductID)
+	}
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	reviews := make([]models.Review, 0, q.Limit)
+	err := tx.Order("id DESC").Offset(q.Offset).Limit(q.Limit).Find(&reviews).Error
+	return reviews, total, err
+}
+
+func (r *ReviewRepository) UpdateStatus(review *models.Review, status models.ReviewStatus) error {
+	return r.db.Model(review).Update("status", status).Error
+}
+
+func (r *ReviewRepository) Delete(review *models.Review) error {
+	return r.db.Delete(review).Error
+}
+
+// RefreshProductRating menghitung ulang rata-rata dan jumlah rating produk dari review
+// yang dipublikasikan. Versi produk tidak berubah karena bukan perubahan data produk.
+func (r *ReviewRepository) RefreshProductRating(productID uint) error {
+	published := r.db.Model(&models.Review{}).Where("product_id = ? AND status = ?", productID, models.ReviewPublished)
+	return r.db.Unscoped().Model(&models.Product{}).Where("id = ?", productID).
+		UpdateColumns(map[string]interface{}{
+			"rating_count":   published.Session(&gorm.Session{}).Select("COUNT(*)"),
+			"rating_average": published.Session(&gorm.Session{}).Select("COALESCE(ROUND(AVG(rating), 2), 0)"),
+		}).Error
+}
+
+func (r *ReviewRepository) WithTx(tx *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: tx}
+}
+
+func (r *ReviewRepository) DB() *gorm.DB {
+	return r.db
+}
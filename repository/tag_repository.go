@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db}
+}
+
+func (r *TagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *TagRepository) FindAll() ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Order("name").Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepository) FindByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.First(&tag, id).Error
+	return &tag, err
+}
+
+func (r *TagRepository) FindByName(name string) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Where("name = ?", name).First(&tag).Error
+	return &tag, err
+}
+
+// FindOrCreateByNames mengembalikan tag dengan nama-nama tersebut, membuat yang belum ada
+func (r *TagRepository) FindOrCreateByNames(names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	if len(names) == 0 {
+		return tags, nil
+	}
+	newTags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		newTags = append(newTags, models.Tag{Name: name})
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error; err != nil {
+		return nil, err
+	}
+	err := r.db.Where("name IN ?", names).Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepository) Update(tag *models.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+// Delete menghapus tag beserta relasinya ke produk
+func (r *TagRepository) Delete(tag *models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(tag).Error
+	})
+}
+
+func (r *TagRepository) WithTx(tx *gorm.DB) *TagRepository {
+	return &TagRepository{db: tx}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/wahyuutomoputra/order-management/models"
+	"gorm.io/gorm"
+)
+
+type WarehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepository(db *gorm.DB) *WarehouseRepository {
+	return &WarehouseRepository{db}
+}
+
+func (r *WarehouseRepository) Create(warehouse *models.Warehouse) error {
+	return r.db.Create(warehouse).Error
+}
+
+// FindAll mengembalikan semua gudang terurut berdasarkan prioritas
+func (r *WarehouseRepository) FindAll() ([]models.Warehouse, error) {
+	var warehouses []models.Warehouse
+	err := r.db.Order("priority").Order("id").Find(&warehouses).Error
+	return warehouses, err
+}
+
+func (r *WarehouseRepository) FindByID(id uint) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.db.First(&warehouse, id).Error
+	return &warehouse, err
+}
+
+func (r *WarehouseRepository) FindByCode(code string) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.db.Where("code = ?", code).First(&warehouse).Error
+	return &warehouse, err
+}
+
+// FindDefault mengembalikan gudang aktif dengan prioritas tertinggi, dipakai untuk
+// stok masuk yang tidak menyebutkan gudang
+func (r *WarehouseRepository) FindDefault() (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.db.Where("active = ?", true).Order("priority").Order("id").First(&warehouse).Error
+	return &warehouse, err
+}
+
+func (r *WarehouseRepository) Update(warehouse *models.Warehouse) error {
+	return r.db.Save(warehouse).Error
+}
+
+func (r *WarehouseRepository) WithTx(tx *gorm.DB) *WarehouseRepository {
+	return &WarehouseRepository{db: tx}
+}
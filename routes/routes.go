@@ -1,9 +1,13 @@
 package routes
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
+	"github.com/wahyuutomoputra/order-management/config"
 	"github.com/wahyuutomoputra/order-management/handler"
 	"github.com/wahyuutomoputra/order-management/middleware"
+	"github.com/wahyuutomoputra/order-management/models"
 	"github.com/wahyuutomoputra/order-management/repository"
 	"github.com/wahyuutomoputra/order-management/service"
 	"gorm.io/gorm"
@@ -15,13 +19,60 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 	userService := service.NewUserService(userRepo)
 	authHandler := handler.NewAuthHandler(userService)
 
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	categoryService := service.NewCategoryService(categoryRepo)
+	tagService := service.NewTagService(tagRepo)
+	categoryHandler := handler.NewCategoryHandler(categoryService, tagService)
+
 	productRepo := repository.NewProductRepository(db)
-	productService := service.NewProductService(productRepo)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	productService := service.NewProductService(productRepo, inventoryRepo, categoryRepo, tagRepo)
 	productHandler := handler.NewProductHandler(productService)
+	imageConfig := config.LoadImageConfig()
+	imageStorage := service.NewStorage(imageConfig)
+	// URL gambar di response produk dibentuk oleh storage yang dipakai
+	models.ImageURL = imageStorage.URL
+	productService.SetImageStorage(imageStorage)
+	imageService := service.NewImageService(repository.NewImageRepository(db), productRepo, imageStorage, imageConfig.MaxSize)
+	imageHandler := handler.NewImageHandler(imageService)
+	searchConfig := config.LoadSearchConfig()
+	searchIndex := service.NewSearchIndex(searchConfig.Backend, productRepo)
+	if memoryIndex, ok := searchIndex.(*service.MemorySearchIndex); ok {
+		// index memory dimuat dari database dan diperbarui berkala di background
+		go memoryIndex.RunRefresher(context.Background(), searchConfig.RefreshInterval, productRepo)
+	}
+	searchHandler := handler.NewSearchHandler(service.NewSearchService(searchIndex))
+	warehouseRepo := repository.NewWarehouseRepository(db)
+	warehouseService := service.NewWarehouseService(warehouseRepo)
+	warehouseHandler := handler.NewWarehouseHandler(warehouseService)
+	inventoryService := service.NewInventoryService(inventoryRepo, productRepo, warehouseRepo)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
 
+	alertConfig := config.LoadAlertConfig()
+	alertRepo := repository.NewAlertRepository(db)
+	alertService := service.NewAlertService(alertRepo, service.NewNotifier(alertConfig, alertRepo))
+	// dispatcher mengirim event low-stock yang tercatat saat stok berubah
+	go alertService.RunDispatcher(context.Background(), alertConfig.DispatchInterval)
+
+	orderConfig := config.LoadOrderConfig()
 	orderRepo := repository.NewOrderRepository(db)
-	orderService := service.NewOrderService(orderRepo)
-	orderHandler := handler.NewOrderHandler(orderService)
+	reservationRepo := repository.NewReservationRepository(db)
+	reservationService := service.NewReservationService(reservationRepo, inventoryRepo, productRepo,
+		service.NewAllocationStrategy(orderConfig.AllocationStrategy), orderConfig.ReservationTTL)
+	// sweeper berjalan di proses server untuk melepas reservasi yang kedaluwarsa
+	go reservationService.RunSweeper(context.Background(), orderConfig.ReservationSweepInterval)
+	orderService := service.NewOrderService(orderRepo, inventoryRepo, reservationRepo, orderConfig)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, orderConfig.IdempotencyKeyTTL, orderConfig.IdempotencyKeyLease)
+	orderHandler := handler.NewOrderHandler(orderService, idempotencyService)
+
+	reviewService := service.NewReviewService(repository.NewReviewRepository(db), orderRepo, productRepo)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+
+	cartRepo := repository.NewCartRepository(db)
+	cartService := service.NewCartService(cartRepo, productRepo, orderService, reservationService)
+	cartHandler := handler.NewCartHandler(cartService)
 
 	r.POST("/register", authHandler.RegisterHandler())
 	r.POST("/login", authHandler.LoginHandler())
@@ -31,16 +82,72 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 	product := r.Group("/products")
 	{
 		product.GET("", productHandler.ListProductHandler())
+		product.GET("/search", searchHandler.SearchProductsHandler())
 		product.GET(":id", productHandler.GetProductHandler())
+		product.GET("/:id/reviews", reviewHandler.ListProductReviewsHandler())
+		product.POST("/:id/reviews", middleware.AuthMiddleware(), reviewHandler.CreateReviewHandler())
 	}
+	r.GET("/images/*key", imageHandler.ServeImageHandler())
+	r.GET("/categories", categoryHandler.ListCategoriesHandler())
+	r.GET("/tags", categoryHandler.ListTagsHandler())
 
 	admin := r.Group("/admin", middleware.AuthMiddleware(), handler.AdminOnly())
 	{
 		admin.POST("/products", productHandler.CreateProductHandler())
+		admin.GET("/products/archived", productHandler.ListArchivedProductHandler())
+		admin.POST("/products/import", productHandler.ImportProductsHandler())
+		admin.GET("/products/export", productHandler.ExportProductsHandler())
 		admin.PUT("/products/:id", productHandler.UpdateProductHandler())
+		admin.PATCH("/products/:id", productHandler.PatchProductHandler())
 		admin.DELETE("/products/:id", productHandler.DeleteProductHandler())
+		admin.POST("/products/:id/restore", productHandler.RestoreProductHandler())
+		admin.DELETE("/products/:id/purge", productHandler.PurgeProductHandler())
+		admin.POST("/products/:id/stock", inventoryHandler.AdjustStockHandler())
+		admin.GET("/products/:id/movements", inventoryHandler.ProductMovementsHandler())
+		admin.GET("/inventory/reconcile", inventoryHandler.ReconcileInventoryHandler())
+		admin.GET("/inventory/low-stock", inventoryHandler.LowStockHandler())
+		admin.GET("/warehouses", warehouseHandler.ListWarehousesHandler())
+		admin.POST("/warehouses", warehouseHandler.CreateWarehouseHandler())
+		admin.PUT("/warehouses/:id", warehouseHandler.UpdateWarehouseHandler())
+		admin.POST("/products/:id/variants", productHandler.CreateVariantHandler())
+		admin.PUT("/products/:id/variants/:variant_id", productHandler.UpdateVariantHandler())
+		admin.DELETE("/products/:id/variants/:variant_id", productHandler.DeleteVariantHandler())
+		admin.POST("/products/:id/images", imageHandler.UploadImageHandler())
+		admin.PUT("/products/:id/images/order", imageHandler.ReorderImagesHandler())
+		admin.PUT("/products/:id/images/:image_id/primary", imageHandler.SetPrimaryImageHandler())
+		admin.DELETE("/products/:id/images/:image_id", imageHandler.DeleteImageHandler())
+
+		admin.POST("/categories", categoryHandler.CreateCategoryHandler())
+		admin.PUT("/categories/:id", categoryHandler.UpdateCategoryHandler())
+		admin.DELETE("/categories/:id", categoryHandler.DeleteCategoryHandler())
+		admin.POST("/tags", categoryHandler.CreateTagHandler())
+		admin.PUT("/tags/:id", categoryHandler.UpdateTagHandler())
+		admin.DELETE("/tags/:id", categoryHandler.DeleteTagHandler())
+
+		admin.GET("/reviews", reviewHandler.ListReviewsHandler())
+		admin.PUT("/reviews/:id/status", reviewHandler.UpdateReviewStatusHandler())
+		admin.DELETE("/reviews/:id", reviewHandler.DeleteReviewHandler())
+
+		admin.GET("/orders", orderHandler.ListOrdersHandler())
+		admin.PUT("/orders/:id/status", orderHandler.UpdateOrderStatusHandler())
+		admin.GET("/orders/:id/history", orderHandler.OrderStatusHistoryHandler())
 	}
 
 	r.POST("/orders", middleware.AuthMiddleware(), orderHandler.CreateOrderHandler())
 	r.GET("/orders/history", middleware.AuthMiddleware(), orderHandler.OrderHistoryHandler())
+	r.GET("/orders/:id", middleware.AuthMiddleware(), orderHandler.GetOrderHandler())
+	r.POST("/orders/:id/cancel", middleware.AuthMiddleware(), orderHandler.CancelOrderHandler())
+
+	cart := r.Group("/cart", middleware.AuthMiddleware())
+	{
+		cart.GET("", cartHandler.GetCartHandler())
+		cart.DELETE("", cartHandler.ClearCartHandler())
+		cart.POST("/items", cartHandler.AddCartItemHandler())
+		cart.PUT("/items/:product_id", cartHandler.UpdateCartItemHandler())
+		cart.DELETE("/items/:product_id", cartHandler.RemoveCartItemHandler())
+		cart.POST("/reservation", cartHandler.ReserveCartHandler())
+		cart.GET("/reservation", cartHandler.GetReservationHandler())
+		cart.DELETE("/reservation", cartHandler.ReleaseReservationHandler())
+		cart.POST("/checkout", cartHandler.CheckoutCartHandler())
+	}
 }
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+// maxAlertAttempts adalah batas percobaan kirim sebuah event sebelum diabaikan
+const maxAlertAttempts = 5
+
+// AlertService mengirim event low-stock yang tercatat oleh InventoryRepository ke notifier
+type AlertService struct {
+	repo     *repository.AlertRepository
+	notifier Notifier
+}
+
+func NewAlertService(repo *repository.AlertRepository, notifier Notifier) *AlertService {
+	return &AlertService{repo: repo, notifier: notifier}
+}
+
+// DispatchPending mengirim event yang belum terkirim dan mengembalikan jumlah yang berhasil
+func (s *AlertService) DispatchPending(ctx context.Context) (int, error) {
+	events, err := s.repo.FindPendingEvents(maxAlertAttempts, 100)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for i := range events {
+		if err := s.notifier.Notify(ctx, events[i]); err != nil {
+			if err := s.repo.MarkFailed(&events[i], err.Error()); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := s.repo.MarkDelivered(&events[i], time.Now().Unix()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// RunDispatcher menjalankan DispatchPending secara berkala sampai ctx selesai.
+// Dipanggil sebagai goroutine saat server start; interval <= 0 mematikan dispatcher.
+func (s *AlertService) RunDispatcher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DispatchPending(ctx); err != nil {
+				log.Printf("low-stock dispatcher: %v", err)
+			}
+		}
+	}
+}
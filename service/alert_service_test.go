@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/wahyuutomoputra/order-management/config"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+// failingNotifier selalu gagal mengirim sehingga event tetap menunggu dicoba ulang
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(context.Context, models.LowStockEvent) error {
+	return errors.New("notifier unavailable")
+}
+
+func TestLowStockEventOnlyOnThresholdCrossing(t *testing.T) {
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, 10)
+	if err := db.Model(&product).Update("reorder_threshold", 5).Error; err != nil {
+		t.Fatal(err)
+	}
+	inventory := NewInventoryService(repository.NewInventoryRepository(db), repository.NewProductRepository(db),
+		repository.NewWarehouseRepository(db))
+	orders := NewOrderService(repository.NewOrderRepository(db), repository.NewInventoryRepository(db),
+		repository.NewReservationRepository(db), config.OrderConfig{})
+
+	steps := []struct {
+		name       string
+		delta      int
+		wantEvents int
+	}{
+		{"sale above threshold", -4, 0},    // 10 -> 6
+		{"sale crossing threshold", -2, 1}, // 6 -> 4
+		{"sale below threshold", -1, 1},    // 4 -> 3, sudah di bawah threshold
+		{"restock above threshold", 10, 1}, // 3 -> 13
+		{"sale to threshold", -8, 2},       // 13 -> 5, turun lagi melewati threshold
+	}
+	for _, step := range steps {
+		var err error
+		if step.delta < 0 {
+			_, err = orders.CreateOrder(1, []dto.OrderItemInput{{ProductID: product.ID, Quantity: -step.delta}})
+		} else {
+			_, err = inventory.Adjust(product.ID, dto.StockAdjustmentRequest{Delta: step.delta, Reason: "restock"}, 1)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		var events int64
+		if err := db.Model(&models.LowStockEvent{}).Where("product_id = ?", product.ID).Count(&events).Error; err != nil {
+			t.Fatal(err)
+		}
+		if int(events) != step.wantEvents {
+			t.Errorf("%s: low-stock events = %d, want %d", step.name, events, step.wantEvents)
+		}
+	}
+
+	var last models.LowStockEvent
+	if err := db.Where("product_id = ?", product.ID).Order("id DESC").First(&last).Error; err != nil {
+		t.Fatal(err)
+	}
+	if last.Stock != 5 || last.Threshold != 5 {
+		t.Errorf("last event stock %d threshold %d, want 5 and 5", last.Stock, last.Threshold)
+	}
+}
+
+func TestDispatchPendingDeliversToEmailOutbox(t *testing.T) {
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, 3)
+	if err := db.Model(&product).Update("reorder_threshold", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	inventory := NewInventoryService(repository.NewInventoryRepository(db), repository.NewProductRepository(db),
+		repository.NewWarehouseRepository(db))
+	if _, err := inventory.Adjust(product.ID, dto.StockAdjustmentRequest{Delta: -2, Reason: "adjustment"}, 1); err != nil {
+		t.Fatalf("adjust: %v", err)
+	}
+	alertRepo := repository.NewAlertRepository(db)
+	event := func() models.LowStockEvent {
+		t.Helper()
+		var event models.LowStockEvent
+		if err := db.Where("product_id = ?", product.ID).First(&event).Error; err != nil {
+			t.Fatalf("find low-stock event: %v", err)
+		}
+		return event
+	}
+
+	// notifier yang gagal menambah attempts dan event tetap menunggu
+	if _, err := NewAlertService(alertRepo, failingNotifier{}).DispatchPending(context.Background()); err != nil {
+		t.Fatalf("dispatch with failing notifier: %v", err)
+	}
+	if got := event(); got.DeliveredAt != 0 || got.Attempts != 1 || got.LastError != "notifier unavailable" {
+		t.Errorf("event after failed delivery = %+v, want pending with one attempt", got)
+	}
+
+	notifier := NewNotifier(config.AlertConfig{Notifier: "email", EmailTo: "ops@example.com"}, alertRepo)
+	alerts := NewAlertService(alertRepo, notifier)
+	if _, err := alerts.DispatchPending(context.Background()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if got := event(); got.DeliveredAt == 0 || got.Attempts != 2 {
+		t.Errorf("event after delivery = %+v, want delivered on the second attempt", got)
+	}
+	var emails []models.EmailOutbox
+	if err := db.Where("body LIKE ?", fmt.Sprintf("%%(product #%d)%%", product.ID)).Find(&emails).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(emails) != 1 || emails[0].To != "ops@example.com" {
+		t.Fatalf("outbox emails = %+v, want one email to ops@example.com", emails)
+	}
+
+	// event yang sudah terkirim tidak dikirim ulang
+	if _, err := alerts.DispatchPending(context.Background()); err != nil {
+		t.Fatalf("dispatch again: %v", err)
+	}
+	var count int64
+	if err := db.Model(&models.EmailOutbox{}).Where("body LIKE ?", fmt.Sprintf("%%(product #%d)%%", product.ID)).
+		Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("outbox emails after second dispatch = %d, want 1", count)
+	}
+}
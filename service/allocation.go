@@ -0,0 +1,64 @@
+package service
+
+import "github.com/wahyuutomoputra/order-management/models"
+
+// Allocation adalah jumlah unit yang diambil dari satu gudang
+type Allocation struct {
+	WarehouseID uint
+	Quantity    int
+}
+
+// AllocationStrategy memilih gudang pengirim untuk satu baris order. levels berisi stok
+// di gudang aktif, sudah terurut berdasarkan prioritas gudang. Mengembalikan false jika
+// stok gabungan tidak mencukupi.
+type AllocationStrategy interface {
+	Allocate(levels []models.StockLevel, quantity int) ([]Allocation, bool)
+}
+
+// allocationStrategies adalah strategi yang bisa dipilih lewat env ORDER_ALLOCATION_STRATEGY
+var allocationStrategies = map[string]AllocationStrategy{
+	"single_first": SingleWarehouseFirst{},
+	"priority":     PriorityFill{},
+}
+
+// NewAllocationStrategy mengembalikan strategi berdasarkan nama; nama yang tidak
+// dikenal memakai SingleWarehouseFirst
+func NewAllocationStrategy(name string) AllocationStrategy {
+	if strategy, ok := allocationStrategies[name]; ok {
+		return strategy
+	}
+	return SingleWarehouseFirst{}
+}
+
+// SingleWarehouseFirst mengirim dari satu gudang dengan prioritas tertinggi yang stoknya
+// cukup. Jika tidak ada, pesanan dipecah mengikuti urutan prioritas gudang.
+type SingleWarehouseFirst struct{}
+
+func (SingleWarehouseFirst) Allocate(levels []models.StockLevel, quantity int) ([]Allocation, bool) {
+	for _, level := range levels {
+		if level.Quantity >= quantity {
+			return []Allocation{{WarehouseID: level.WarehouseID, Quantity: quantity}}, true
+		}
+	}
+	return PriorityFill{}.Allocate(levels, quantity)
+}
+
+// PriorityFill menghabiskan stok gudang satu per satu mengikuti urutan prioritas
+type PriorityFill struct{}
+
+func (PriorityFill) Allocate(levels []models.StockLevel, quantity int) ([]Allocation, bool) {
+	var allocations []Allocation
+	remaining := quantity
+	for _, level := range levels {
+		if remaining == 0 {
+			break
+		}
+		if level.Quantity <= 0 {
+			continue
+		}
+		take := min(level.Quantity, remaining)
+		allocations = append(allocations, Allocation{WarehouseID: level.WarehouseID, Quantity: take})
+		remaining -= take
+	}
+	return allocations, remaining == 0
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+// levels membuat stok per gudang; warehouse ID mengikuti urutan argumen mulai dari 1
+func levels(quantities ...int) []models.StockLevel {
+	result := make([]models.StockLevel, len(quantities))
+	for i, quantity := range quantities {
+		result[i] = models.StockLevel{WarehouseID: uint(i + 1), Quantity: quantity}
+	}
+	return result
+}
+
+func TestAllocationStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy AllocationStrategy
+		levels   []models.StockLevel
+		quantity int
+		want     []Allocation
+		wantOK   bool
+	}{
+		{"single first: highest priority with enough stock", SingleWarehouseFirst{}, levels(5, 5), 3,
+			[]Allocation{{1, 3}}, true},
+		{"single first: skips warehouse without enough stock", SingleWarehouseFirst{}, levels(2, 5, 10), 4,
+			[]Allocation{{2, 4}}, true},
+		{"single first: exact stock", SingleWarehouseFirst{}, levels(2, 4), 4,
+			[]Allocation{{2, 4}}, true},
+		{"single first: splits in priority order when no warehouse is enough", SingleWarehouseFirst{}, levels(3, 4, 2), 8,
+			[]Allocation{{1, 3}, {2, 4}, {3, 1}}, true},
+		{"single first: insufficient combined stock", SingleWarehouseFirst{}, levels(3, 4, 2), 10,
+			nil, false},
+		{"single first: no warehouse", SingleWarehouseFirst{}, nil, 1,
+			nil, false},
+		{"priority: drains highest priority first", PriorityFill{}, levels(5, 5), 3,
+			[]Allocation{{1, 3}}, true},
+		{"priority: splits even if a later warehouse has enough", PriorityFill{}, levels(2, 10), 4,
+			[]Allocation{{1, 2}, {2, 2}}, true},
+		{"priority: skips empty warehouses", PriorityFill{}, levels(0, 3, 0, 5), 4,
+			[]Allocation{{2, 3}, {4, 1}}, true},
+		{"priority: insufficient combined stock", PriorityFill{}, levels(1, 1), 3,
+			nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.strategy.Allocate(tt.levels, tt.quantity)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v (allocations %v)", ok, tt.wantOK, got)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAllocationStrategy(t *testing.T) {
+	tests := map[string]AllocationStrategy{
+		"single_first": SingleWarehouseFirst{},
+		"priority":     PriorityFill{},
+		"":             SingleWarehouseFirst{},
+		"unknown":      SingleWarehouseFirst{},
+	}
+	for name, want := range tests {
+		if got := NewAllocationStrategy(name); got != want {
+			t.Errorf("NewAllocationStrategy(%q) = %T, want %T", name, got, want)
+		}
+	}
+}
+
+// TestAllocationWarehouseOrder memastikan stok yang dialokasikan hanya dari gudang aktif,
+// terurut berdasarkan prioritas lalu ID gudang
+func TestAllocationWarehouseOrder(t *testing.T) {
+	db := openTestDB(t)
+	first := seedWarehouse(t, db, 10)
+	inactive := seedWarehouse(t, db, 0)
+	second := seedWarehouse(t, db, 10)
+	preferred := seedWarehouse(t, db, 5)
+	if err := db.Model(&inactive).Update("active", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	product := seedProduct(t, db, second, 2)
+	restock(t, db, product.ID, inactive.ID, 100)
+	restock(t, db, product.ID, first.ID, 2)
+	restock(t, db, product.ID, preferred.ID, 1)
+
+	var allocations []Allocation
+	err := db.Transaction(func(tx *gorm.DB) error {
+		levels, err := repository.NewInventoryRepository(tx).FindLevelsForUpdate(product.ID, 0)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if allocations, ok = (PriorityFill{}).Allocate(levels, 5); !ok {
+			t.Errorf("allocate 5 from %v: insufficient stock", levels)
+		}
+		if _, ok := (PriorityFill{}).Allocate(levels, 6); ok {
+			t.Error("allocate 6: stock in inactive warehouse must not be used")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Allocation{{preferred.ID, 1}, {first.ID, 2}, {second.ID, 2}}
+	if !reflect.DeepEqual(allocations, want) {
+		t.Errorf("allocations = %v, want %v", allocations, want)
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCartEmpty        = errors.New("cart is empty")
+	ErrCartItemNotFound = errors.New("cart item not found")
+	ErrProductNotFound  = repository.ErrProductNotFound
+	ErrVariantRequired  = errors.New("variant is required for this product")
+)
+
+type CartService struct {
+	repo               *repository.CartRepository
+	productRepo        *repository.ProductRepository
+	orderService       *OrderService
+	reservationService *ReservationService
+}
+
+func NewCartService(repo *repository.CartRepository, productRepo *repository.ProductRepository, orderService *OrderService, reservationService *ReservationService) *CartService {
+	return &CartService{repo: repo, productRepo: productRepo, orderService: orderService, reservationService: reservationService}
+}
+
+func (s *CartService) AddItem(userID uint, req dto.CartItemRequest) error {
+	product, err := s.productRepo.FindByID(req.ProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		return err
+	}
+	if req.VariantID == 0 && len(product.Variants) > 0 {
+		return ErrVariantRequired
+	}
+	if req.VariantID != 0 {
+		found := false
+		for _, v := range product.Variants {
+			found = found || v.ID == req.VariantID
+		}
+		if !found {
+			return ErrVariantNotFound
+		}
+	}
+	return s.repo.AddQuantity(userID, req.ProductID, req.VariantID, req.Quantity)
+}
+
+func (s *CartService) UpdateItem(userID, productID, variantID uint, quantity int) error {
+	ok, err := s.repo.SetQuantity(userID, productID, variantID, quantity)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCartItemNotFound
+	}
+	return nil
+}
+
+func (s *CartService) RemoveItem(userID, productID, variantID uint) error {
+	ok, err := s.repo.Delete(userID, productID, variantID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCartItemNotFound
+	}
+	return nil
+}
+
+func (s *CartService) Clear(userID uint) error {
+	return s.repo.ClearByUser(userID)
+}
+
+// GetCart mengembalikan isi keranjang dengan harga dan ketersediaan stok terkini. Stok
+// yang ditampilkan adalah stok yang bisa dibeli user ini, yaitu tanpa stok yang sedang
+// ditahan reservasi customer lain.
+func (s *CartService) GetCart(userID uint) (*dto.CartResponse, error) {
+	items, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(items))
+	var variantIDs []uint
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+		if item.VariantID != 0 {
+			variantIDs = append(variantIDs, item.VariantID)
+		}
+	}
+	products, err := s.productRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+	variants, err := s.productRepo.FindVariantsByIDs(variantIDs)
+	if err != nil {
+		return nil, err
+	}
+	variantByID := make(map[uint]models.ProductVariant, len(variants))
+	for _, v := range variants {
+		variantByID[v.ID] = v
+	}
+
+	cart := &dto.CartResponse{Items: []dto.CartItemResponse{}, Total: models.NewMoney(0), Available: len(items) > 0}
+	for _, item := range items {
+		line := dto.CartItemResponse{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity}
+		p, ok := byID[item.ProductID]
+		if ok {
+			line.Name = p.Name
+			line.Price = p.Price
+		}
+		if item.VariantID != 0 {
+			v, found := variantByID[item.VariantID]
+			ok = ok && found
+			if found {
+				line.SKU = v.SKU
+				line.Options = v.Options
+				line.Price = v.EffectivePrice(p)
+			}
+		}
+		if ok {
+			stock, err := s.reservationService.Available(item.ProductID, item.VariantID, userID)
+			if err != nil {
+				return nil, err
+			}
+			line.Stock = stock
+			line.LineTotal = line.Price.Mul(item.Quantity)
+			line.Available = line.Stock >= item.Quantity
+		}
+		if !line.Available {
+			cart.Available = false
+		}
+		cart.Total = cart.Total.Add(line.LineTotal)
+		cart.Items = append(cart.Items, line)
+	}
+	return cart, nil
+}
+
+// Reserve menahan stok untuk seluruh isi keranjang selama TTL reservasi, dipanggil saat
+// customer mulai checkout
+func (s *CartService) Reserve(userID uint) (*dto.ReservationResponse, error) {
+	items, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrCartEmpty
+	}
+	inputs, _ := cartOrderInputs(items)
+	return s.reservationService.Reserve(userID, inputs)
+}
+
+// Reservation mengembalikan reservasi checkout yang masih aktif
+func (s *CartService) Reservation(userID uint) (*dto.ReservationResponse, error) {
+	return s.reservationService.Active(userID)
+}
+
+// ReleaseReservation melepas stok yang ditahan untuk checkout
+func (s *CartService) ReleaseReservation(userID uint) error {
+	return s.reservationService.Release(userID)
+}
+
+// Checkout mengubah keranjang menjadi order dan mengosongkan keranjang dalam satu transaksi.
+// Reservasi aktif customer untuk item keranjang dikonversi menjadi pengurangan stok.
+// Baris keranjang dikunci dan dibaca di dalam transaksi order, sehingga quantity yang
+// ditambahkan bersamaan tidak ikut terhapus tanpa masuk ke order.
+func (s *CartService) Checkout(userID uint) (*models.Order, error) {
+	var ids []uint
+	return s.orderService.CreateOrderFrom(userID, func(tx *gorm.DB) ([]dto.OrderItemInput, error) {
+		items, err := s.repo.WithTx(tx).FindByUserForUpdate(userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return nil, ErrCartEmpty
+		}
+		var inputs []dto.OrderItemInput
+		inputs, ids = cartOrderInputs(items)
+		return inputs, nil
+	}, func(tx *gorm.DB, order *models.Order) error {
+		return s.repo.WithTx(tx).DeleteByIDs(userID, ids)
+	})
+}
+
+func cartOrderInputs(items []models.CartItem) ([]dto.OrderItemInput, []uint) {
+	inputs := make([]dto.OrderItemInput, 0, len(items))
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		inputs = append(inputs, dto.OrderItemInput{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity})
+		ids = append(ids, item.ID)
+	}
+	return inputs, ids
+}
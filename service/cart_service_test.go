@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/config"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+func TestGetCartExcludesOthersReservations(t *testing.T) {
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, 5)
+
+	productRepo := repository.NewProductRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	orders := NewOrderService(repository.NewOrderRepository(db), inventoryRepo, reservationRepo, config.OrderConfig{})
+	reservations := NewReservationService(reservationRepo, inventoryRepo, productRepo, SingleWarehouseFirst{}, time.Minute)
+	carts := NewCartService(repository.NewCartRepository(db), productRepo, orders, reservations)
+
+	const holder, other = 1, 2
+	for _, userID := range []uint{holder, other} {
+		if err := carts.AddItem(userID, dto.CartItemRequest{ProductID: product.ID, Quantity: 3}); err != nil {
+			t.Fatalf("add item for user %d: %v", userID, err)
+		}
+	}
+	if _, err := carts.Reserve(holder); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+
+	tests := []struct {
+		userID        uint
+		wantStock     int
+		wantAvailable bool
+	}{
+		// reservasi milik sendiri tetap dihitung sebagai stok tersedia
+		{holder, 5, true},
+		{other, 2, false},
+	}
+	for _, tt := range tests {
+		cart, err := carts.GetCart(tt.userID)
+		if err != nil {
+			t.Fatalf("get cart for user %d: %v", tt.userID, err)
+		}
+		line := cart.Items[0]
+		if line.Stock != tt.wantStock || line.Available != tt.wantAvailable || cart.Available != tt.wantAvailable {
+			t.Errorf("user %d: stock = %d, available = %v (cart %v), want %d, %v",
+				tt.userID, line.Stock, line.Available, cart.Available, tt.wantStock, tt.wantAvailable)
+		}
+	}
+	// keranjang yang ditampilkan tidak tersedia juga tidak bisa direservasi
+	if _, err := carts.Reserve(other); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("reserve for other user: error = %v, want ErrInsufficientStock", err)
+	}
+}
+
+// TestCheckoutKeepsConcurrentlyAddedItems memastikan quantity yang ditambahkan ke keranjang
+// selama checkout berjalan masuk ke order atau tetap di keranjang, tidak hilang
+func TestCheckoutKeepsConcurrentlyAddedItems(t *testing.T) {
+	requireMySQL(t)
+	const adds = 20
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, adds+1)
+
+	productRepo := repository.NewProductRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	orders := NewOrderService(repository.NewOrderRepository(db), inventoryRepo, reservationRepo, config.OrderConfig{})
+	reservations := NewReservationService(reservationRepo, inventoryRepo, productRepo, SingleWarehouseFirst{}, time.Minute)
+	carts := NewCartService(repository.NewCartRepository(db), productRepo, orders, reservations)
+
+	// user unik agar keranjang test lain di database bersama tidak ikut di-checkout
+	userID := 1_000_000 + product.ID
+	if err := carts.AddItem(userID, dto.CartItemRequest{ProductID: product.ID, Quantity: 1}); err != nil {
+		t.Fatalf("add item: %v", err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < adds; i++ {
+			if err := carts.AddItem(userID, dto.CartItemRequest{ProductID: product.ID, Quantity: 1}); err != nil {
+				t.Errorf("add item: %v", err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < adds; i++ {
+			if _, err := carts.Checkout(userID); err != nil && !errors.Is(err, ErrCartEmpty) {
+				t.Errorf("checkout: %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	var ordered int64
+	if err := db.Model(&models.OrderItem{}).Where("product_id = ?", product.ID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&ordered).Error; err != nil {
+		t.Fatal(err)
+	}
+	var inCart int64
+	if err := db.Model(&models.CartItem{}).Where("user_id = ? AND product_id = ?", userID, product.ID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&inCart).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ordered+inCart != adds+1 {
+		t.Errorf("ordered %d + in cart %d = %d, want %d", ordered, inCart, ordered+inCart, adds+1)
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryHasChildren = errors.New("category still has sub-categories")
+	ErrCategoryCycle       = errors.New("category cannot be its own ancestor")
+	ErrCategorySlugTaken   = errors.New("category slug already exists")
+	ErrCategorySlugInvalid = errors.New("category slug must contain letters or digits")
+)
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+type CategoryService struct {
+	repo *repository.CategoryRepository
+}
+
+func NewCategoryService(repo *repository.CategoryRepository) *CategoryService {
+	return &CategoryService{repo}
+}
+
+// Tree mengembalikan seluruh kategori dalam bentuk pohon
+func (s *CategoryService) Tree() ([]models.Category, error) {
+	categories, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories, nil), nil
+}
+
+func (s *CategoryService) FindByID(id uint) (*models.Category, error) {
+	category, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *CategoryService) Create(req dto.CategoryRequest) (*models.Category, error) {
+	category := models.Category{Name: strings.TrimSpace(req.Name), Slug: slugify(req.Slug, req.Name)}
+	if err := s.checkSlug(category.Slug, 0); err != nil {
+		return nil, err
+	}
+	if req.ParentID != nil {
+		if _, err := s.FindByID(*req.ParentID); err != nil {
+			return nil, err
+		}
+		category.ParentID = req.ParentID
+	}
+	if err := s.repo.Create(&category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (s *CategoryService) Update(id uint, req dto.CategoryRequest) (*models.Category, error) {
+	category, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	category.Name = strings.TrimSpace(req.Name)
+	category.Slug = slugify(req.Slug, req.Name)
+	if err := s.checkSlug(category.Slug, id); err != nil {
+		return nil, err
+	}
+	category.ParentID = nil
+	if req.ParentID != nil {
+		if _, err := s.FindByID(*req.ParentID); err != nil {
+			return nil, err
+		}
+		categories, err := s.repo.FindAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, descendant := range descendantCategoryIDs(categories, id) {
+			if descendant == *req.ParentID {
+				return nil, ErrCategoryCycle
+			}
+		}
+		category.ParentID = req.ParentID
+	}
+	if err := s.repo.Update(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Delete menghapus kategori yang tidak lagi memiliki sub-kategori
+func (s *CategoryService) Delete(id uint) error {
+	category, err := s.FindByID(id)
+	if err != nil {
+		return err
+	}
+	children, err := s.repo.CountChildren(id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return ErrCategoryHasChildren
+	}
+	return s.repo.Delete(category)
+}
+
+// DescendantIDs mengembalikan ID kategori beserta seluruh turunannya
+func (s *CategoryService) DescendantIDs(id uint) ([]uint, error) {
+	if _, err := s.FindByID(id); err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return descendantCategoryIDs(categories, id), nil
+}
+
+func (s *CategoryService) checkSlug(slug string, exceptID uint) error {
+	if slug == "" {
+		return ErrCategorySlugInvalid
+	}
+	existing, err := s.repo.FindBySlug(slug)
+	if err == nil && existing.ID != exceptID {
+		return ErrCategorySlugTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// buildCategoryTree menyusun kategori dengan parent tertentu beserta anak-anaknya
+func buildCategoryTree(categories []models.Category, parentID *uint) []models.Category {
+	nodes := []models.Category{}
+	for _, c := range categories {
+		if (parentID == nil && c.ParentID == nil) || (parentID != nil && c.ParentID != nil && *c.ParentID == *parentID) {
+			id := c.ID
+			c.Children = buildCategoryTree(categories, &id)
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// descendantCategoryIDs mengembalikan rootID dan semua ID turunannya (BFS)
+func descendantCategoryIDs(categories []models.Category, rootID uint) []uint {
+	children := make(map[uint][]uint)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+	ids := []uint{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// slugify membuat slug dari slug yang diminta, atau dari nama jika kosong
+func slugify(slug, name string) string {
+	if strings.TrimSpace(slug) == "" {
+		slug = name
+	}
+	return strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(slug), "-"), "-")
+}
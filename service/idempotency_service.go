@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still being processed")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used with a different payload")
+)
+
+type IdempotencyService struct {
+	repo  *repository.IdempotencyRepository
+	ttl   time.Duration
+	lease time.Duration
+}
+
+// NewIdempotencyService membuat service idempotency. ttl adalah lama hasil request
+// disimpan; lease adalah lama sebuah request memegang key yang sedang diproses sebelum
+// key boleh diambil alih, sehingga harus lebih lama dari waktu pembuatan order.
+func NewIdempotencyService(repo *repository.IdempotencyRepository, ttl, lease time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, ttl: ttl, lease: lease}
+}
+
+// Begin mengklaim key untuk request baru. Jika key sudah pernah selesai diproses
+// dengan payload yang sama, record lama dikembalikan dengan replay = true. Key yang
+// belum selesai tetapi lease-nya sudah habis diambil alih oleh request ini.
+func (s *IdempotencyService) Begin(userID uint, key, requestHash string) (record *models.IdempotencyKey, replay bool, err error) {
+	now := time.Now()
+	if err := s.repo.DeleteExpiredByUser(userID, now.Unix()); err != nil {
+		return nil, false, err
+	}
+	record = &models.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		LockedUntil: now.Add(s.lease).Unix(),
+		ExpiresAt:   now.Add(s.ttl).Unix(),
+	}
+	inserted, err := s.repo.Insert(record)
+	if err != nil {
+		return nil, false, err
+	}
+	if inserted {
+		return record, false, nil
+	}
+
+	existing, err := s.repo.FindByUserKey(userID, key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// key baru saja dilepas oleh request lain yang gagal
+			return nil, false, ErrIdempotencyInProgress
+		}
+		return nil, false, err
+	}
+	if existing.RequestHash != requestHash {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		if existing.LockedUntil > now.Unix() {
+			return nil, false, ErrIdempotencyInProgress
+		}
+		// request sebelumnya tidak selesai dalam lease-nya (misal proses mati), ambil alih key
+		lockedUntil := now.Add(s.lease).Unix()
+		taken, err := s.repo.TakeOver(existing, now.Unix(), lockedUntil)
+		if err != nil {
+			return nil, false, err
+		}
+		if !taken {
+			return nil, false, ErrIdempotencyInProgress
+		}
+		existing.LockedUntil = lockedUntil
+		return existing, false, nil
+	}
+	return existing, true, nil
+}
+
+// Complete menyimpan response untuk key. Jika tx tidak nil, penyimpanan ikut
+// transaksi tersebut sehingga atomik dengan perubahan data yang dihasilkan request.
+// Mengembalikan ErrIdempotencyInProgress jika key sudah diambil alih request lain
+// karena lease-nya habis, sehingga transaksi tx harus dibatalkan.
+func (s *IdempotencyService) Complete(tx *gorm.DB, record *models.IdempotencyKey, statusCode int, body []byte) error {
+	repo := s.repo
+	if tx != nil {
+		repo = repo.WithTx(tx)
+	}
+	saved, err := repo.SaveResponse(record, statusCode, body)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return ErrIdempotencyInProgress
+	}
+	record.StatusCode = statusCode
+	record.ResponseBody = body
+	return nil
+}
+
+// Release melepas key dari request yang gagal karena error server agar client bisa
+// mencoba lagi. Request yang ditolak karena isinya disimpan lewat Complete.
+func (s *IdempotencyService) Release(record *models.IdempotencyKey) error {
+	return s.repo.Release(record)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+func TestIdempotencyLeaseTakeOver(t *testing.T) {
+	db := openTestDB(t)
+	keys := NewIdempotencyService(repository.NewIdempotencyRepository(db), time.Hour, time.Minute)
+	userID := uint(time.Now().UnixNano() % 1_000_000_000)
+
+	first, replay, err := keys.Begin(userID, "order-1", "hash")
+	if err != nil || replay {
+		t.Fatalf("first Begin = replay %v, error %v", replay, err)
+	}
+	if _, _, err := keys.Begin(userID, "order-1", "hash"); !errors.Is(err, ErrIdempotencyInProgress) {
+		t.Fatalf("Begin during lease: error = %v, want ErrIdempotencyInProgress", err)
+	}
+
+	// request pertama mati tanpa menyimpan response dan lease-nya habis
+	expired := time.Now().Add(-time.Second).Unix()
+	if err := db.Model(&models.IdempotencyKey{}).Where("id = ?", first.ID).Update("locked_until", expired).Error; err != nil {
+		t.Fatal(err)
+	}
+	first.LockedUntil = expired
+	second, replay, err := keys.Begin(userID, "order-1", "hash")
+	if err != nil || replay {
+		t.Fatalf("Begin after lease expired = replay %v, error %v", replay, err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("take over created key %d, want existing key %d", second.ID, first.ID)
+	}
+
+	// pemilik lama tidak boleh lagi menyimpan response atau melepas key
+	if err := keys.Complete(nil, first, 201, []byte(`{"stale":true}`)); !errors.Is(err, ErrIdempotencyInProgress) {
+		t.Errorf("Complete by previous owner: error = %v, want ErrIdempotencyInProgress", err)
+	}
+	if err := keys.Release(first); err != nil {
+		t.Fatalf("Release by previous owner: %v", err)
+	}
+	if err := keys.Complete(nil, second, 201, []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Complete by new owner: %v", err)
+	}
+	record, replay, err := keys.Begin(userID, "order-1", "hash")
+	if err != nil || !replay {
+		t.Fatalf("Begin after complete = replay %v, error %v", replay, err)
+	}
+	if record.StatusCode != 201 || string(record.ResponseBody) != `{"ok":true}` {
+		t.Errorf("replayed %d %s, want 201 {\"ok\":true}", record.StatusCode, record.ResponseBody)
+	}
+}
+
+func TestIdempotencyRelease(t *testing.T) {
+	db := openTestDB(t)
+	keys := NewIdempotencyService(repository.NewIdempotencyRepository(db), time.Hour, time.Minute)
+	userID := uint(time.Now().UnixNano() % 1_000_000_000)
+
+	record, _, err := keys.Begin(userID, "order-2", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Release(record); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, replay, err := keys.Begin(userID, "order-2", "hash"); err != nil || replay {
+		t.Errorf("Begin after release = replay %v, error %v, want a new claim", replay, err)
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+// maxImagesPerProduct membatasi jumlah gambar satu produk
+const maxImagesPerProduct = 20
+
+var (
+	ErrImageNotFound        = errors.New("product image not found")
+	ErrImageTooLarge        = errors.New("image exceeds the maximum size")
+	ErrUnsupportedImageType = errors.New("unsupported image type, use JPEG, PNG, GIF or WebP")
+	ErrTooManyImages        = fmt.Errorf("a product can have at most %d images", maxImagesPerProduct)
+	ErrInvalidImageOrder    = errors.New("image_ids must list every image of the product exactly once")
+)
+
+// imageExtensions adalah tipe gambar yang diterima (hasil sniffing isi file) beserta ekstensinya
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type ImageService struct {
+	repo        *repository.ImageRepository
+	productRepo *repository.ProductRepository
+	storage     Storage
+	maxSize     int64
+}
+
+func NewImageService(repo *repository.ImageRepository, productRepo *repository.ProductRepository, storage Storage, maxSize int64) *ImageService {
+	return &ImageService{repo: repo, productRepo: productRepo, storage: storage, maxSize: maxSize}
+}
+
+// MaxSize mengembalikan ukuran maksimum satu file gambar dalam byte
+func (s *ImageService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload menyimpan gambar baru di urutan terakhir. Tipe file ditentukan dari isinya,
+// bukan dari Content-Type request. Gambar pertama produk otomatis menjadi gambar utama.
+func (s *ImageService) Upload(ctx context.Context, productID uint, r io.Reader, primary bool) (*models.ProductImage, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, ErrImageTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedImageType
+	}
+	if _, err := s.productRepo.FindByID(productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	key, err := newImageKey(productID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	image := &models.ProductImage{
+		ProductID:   productID,
+		StorageKey:  key,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	err = runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		// lock baris produk agar posisi gambar yang diupload bersamaan tidak bentrok
+		if _, err := s.productRepo.WithTx(tx).FindForUpdate(productID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		repoTx := s.repo.WithTx(tx)
+		count, err := repoTx.Count(productID)
+		if err != nil {
+			return err
+		}
+		if count >= maxImagesPerProduct {
+			return ErrTooManyImages
+		}
+		if image.Position, err = repoTx.NextPosition(productID); err != nil {
+			return err
+		}
+		image.IsPrimary = primary || count == 0
+		if err := repoTx.Create(image); err != nil {
+			return err
+		}
+		if image.IsPrimary && count > 0 {
+			return repoTx.SetPrimary(productID, image.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		// file yang sudah tersimpan dibuang agar tidak menjadi file yatim
+		s.deleteFiles(ctx, key)
+		return nil, err
+	}
+	image.URL = s.storage.URL(key)
+	return image, nil
+}
+
+// Reorder mengubah urutan gambar sesuai imageIDs, yang harus berisi semua gambar produk
+func (s *ImageService) Reorder(productID uint, imageIDs []uint) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		if _, err := s.productRepo.WithTx(tx).FindForUpdate(productID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		repoTx := s.repo.WithTx(tx)
+		current, err := repoTx.FindByProduct(productID)
+		if err != nil {
+			return err
+		}
+		if len(imageIDs) != len(current) {
+			return ErrInvalidImageOrder
+		}
+		owned := make(map[uint]bool, len(current))
+		for _, image := range current {
+			owned[image.ID] = true
+		}
+		for i, id := range imageIDs {
+			if !owned[id] {
+				return ErrInvalidImageOrder
+			}
+			delete(owned, id)
+			if err := repoTx.UpdatePosition(id, i+1); err != nil {
+				return err
+			}
+		}
+		images, err = repoTx.FindByProduct(productID)
+		return err
+	})
+	return images, err
+}
+
+// SetPrimary menjadikan satu gambar sebagai gambar utama produk
+func (s *ImageService) SetPrimary(productID, imageID uint) (*models.ProductImage, error) {
+	var image *models.ProductImage
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		var err error
+		image, err = repoTx.FindByID(productID, imageID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrImageNotFound
+			}
+			return err
+		}
+		image.IsPrimary = true
+		return repoTx.SetPrimary(productID, imageID)
+	})
+	return image, err
+}
+
+// Delete menghapus gambar beserta filenya. Jika gambar utama dihapus, gambar pertama
+// yang tersisa menjadi gambar utama.
+func (s *ImageService) Delete(ctx context.Context, productID, imageID uint) error {
+	var key string
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		if _, err := s.productRepo.WithTx(tx).FindForUpdate(productID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		repoTx := s.repo.WithTx(tx)
+		image, err := repoTx.FindByID(productID, imageID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrImageNotFound
+			}
+			return err
+		}
+		if err := repoTx.Delete(image); err != nil {
+			return err
+		}
+		key = image.StorageKey
+		if !image.IsPrimary {
+			return nil
+		}
+		remaining, err := repoTx.FindByProduct(productID)
+		if err != nil || len(remaining) == 0 {
+			return err
+		}
+		return repoTx.SetPrimary(productID, remaining[0].ID)
+	})
+	if err != nil {
+		return err
+	}
+	// file dihapus setelah commit sehingga rollback tidak meninggalkan baris tanpa file
+	s.deleteFiles(ctx, key)
+	return nil
+}
+
+// Open membuka file gambar untuk dikirim ke client
+func (s *ImageService) Open(ctx context.Context, key string) (io.ReadSeekCloser, time.Time, error) {
+	return s.storage.Open(ctx, key)
+}
+
+// deleteFiles menghapus file dari storage; kegagalan hanya dicatat karena datanya sudah tidak dirujuk
+func (s *ImageService) deleteFiles(ctx context.Context, keys ...string) {
+	deleteStoredFiles(ctx, s.storage, keys)
+}
+
+func deleteStoredFiles(ctx context.Context, storage Storage, keys []string) {
+	for _, key := range keys {
+		if err := storage.Delete(ctx, key); err != nil {
+			log.Printf("image storage: failed to delete %s: %v", key, err)
+		}
+	}
+}
+
+// newImageKey membuat storage key acak untuk gambar produk
+func newImageKey(productID uint, ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("products/%d/%s%s", productID, hex.EncodeToString(buf), ext), nil
+}
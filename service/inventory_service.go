@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// InventoryService membaca ledger inventori dan menangani penyesuaian stok manual.
+// Perubahan stok lain dilakukan oleh ProductService dan OrderService lewat
+// InventoryRepository.Apply.
+type InventoryService struct {
+	repo          *repository.InventoryRepository
+	productRepo   *repository.ProductRepository
+	warehouseRepo *repository.WarehouseRepository
+}
+
+func NewInventoryService(repo *repository.InventoryRepository, productRepo *repository.ProductRepository, warehouseRepo *repository.WarehouseRepository) *InventoryService {
+	return &InventoryService{repo: repo, productRepo: productRepo, warehouseRepo: warehouseRepo}
+}
+
+// Movements mengembalikan riwayat perubahan stok produk, termasuk produk arsip
+func (s *InventoryService) Movements(productID uint, page dto.PageQuery) ([]models.InventoryMovement, dto.PageMeta, error) {
+	if _, err := s.productRepo.FindByIDUnscoped(productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.PageMeta{}, ErrProductNotFound
+		}
+		return nil, dto.PageMeta{}, err
+	}
+	page.Normalize()
+	movements, total, err := s.repo.FindByProduct(productID, page.Offset(), page.Limit)
+	if err != nil {
+		return nil, dto.PageMeta{}, err
+	}
+	return movements, dto.NewPageMeta(page, total), nil
+}
+
+// LowStock mengembalikan produk yang stoknya sudah mencapai reorder threshold
+func (s *InventoryService) LowStock(page dto.PageQuery) ([]models.Product, dto.PageMeta, error) {
+	page.Normalize()
+	products, total, err := s.repo.FindLowStock(page.Offset(), page.Limit)
+	if err != nil {
+		return nil, dto.PageMeta{}, err
+	}
+	return products, dto.NewPageMeta(page, total), nil
+}
+
+// Reconcile memastikan stok setiap produk dan varian sama dengan jumlah delta di ledger.
+// productID 0 berarti memeriksa semua produk.
+func (s *InventoryService) Reconcile(productID uint) (*dto.ReconciliationResponse, error) {
+	mismatches, err := s.repo.FindMismatches(productID)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.ReconciliationResponse{Mismatches: make([]dto.StockMismatch, 0, len(mismatches))}
+	for _, m := range mismatches {
+		result.Mismatches = append(result.Mismatches, dto.StockMismatch{
+			ProductID:   m.ProductID,
+			VariantID:   m.VariantID,
+			WarehouseID: m.WarehouseID,
+			Stock:       m.Stock,
+			LedgerStock: m.LedgerStock,
+		})
+	}
+	result.Consistent = len(result.Mismatches) == 0
+	return result, nil
+}
+
+// Adjust menambah atau mengurangi stok produk (atau variannya) secara relatif dan atomik,
+// sehingga tidak menimpa penjualan yang terjadi bersamaan. Pengurangan yang membuat stok
+// negatif di gudang tersebut ditolak dengan ErrInsufficientStock. WarehouseID 0 berarti
+// gudang default.
+func (s *InventoryService) Adjust(productID uint, req dto.StockAdjustmentRequest, actorID uint) (*models.Product, error) {
+	// Apply mengubah stok gudang, varian lalu produk; produk di sini hanya dibaca tanpa lock
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		product, err := s.productRepo.WithTx(tx).FindByID(productID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		if req.WarehouseID != 0 {
+			if _, err := s.warehouseRepo.WithTx(tx).FindByID(req.WarehouseID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrWarehouseNotFound
+				}
+				return err
+			}
+		}
+		movement := &models.InventoryMovement{
+			ProductID:   productID,
+			WarehouseID: req.WarehouseID,
+			Delta:       req.Delta,
+			Reason:      models.MovementReason(req.Reason),
+			ActorID:     actorID,
+			Note:        req.Note,
+		}
+		if req.VariantID != 0 {
+			if !hasVariant(product, req.VariantID) {
+				return ErrVariantNotFound
+			}
+			movement.VariantID = &req.VariantID
+		} else if len(product.Variants) > 0 {
+			return ErrVariantRequired
+		}
+		ok, err := s.repo.WithTx(tx).Apply(movement)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInsufficientStock
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.productRepo.FindByID(productID)
+}
+
+func hasVariant(product *models.Product, variantID uint) bool {
+	for _, variant := range product.Variants {
+		if variant.ID == variantID {
+			return true
+		}
+	}
+	return false
+}
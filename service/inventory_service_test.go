@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/wahyuutomoputra/order-management/config"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+func TestApplyMissingVariantReturnsError(t *testing.T) {
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, 2)
+	missingVariant := uint(1 << 30)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := repository.NewInventoryRepository(tx).Apply(&models.InventoryMovement{
+			ProductID:   product.ID,
+			VariantID:   &missingVariant,
+			WarehouseID: warehouse.ID,
+			Delta:       3,
+			Reason:      models.MovementRestock,
+		})
+		return err
+	})
+	if !errors.Is(err, ErrVariantNotFound) {
+		t.Fatalf("Apply error = %v, want ErrVariantNotFound", err)
+	}
+	// stok gudang yang sempat ditulis ikut di-rollback bersama transaksinya
+	var count int64
+	if err := db.Model(&models.StockLevel{}).Where("product_id = ? AND variant_id = ?", product.ID, missingVariant).
+		Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("stock levels left behind for missing variant: %d", count)
+	}
+}
+
+func TestReconcileDetectsStockOutsideLedger(t *testing.T) {
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, 10)
+	inventory := NewInventoryService(repository.NewInventoryRepository(db), repository.NewProductRepository(db),
+		repository.NewWarehouseRepository(db))
+	orders := NewOrderService(repository.NewOrderRepository(db), repository.NewInventoryRepository(db),
+		repository.NewReservationRepository(db), config.OrderConfig{})
+
+	// penjualan dan penyesuaian manual sama-sama tercatat di ledger
+	if _, err := orders.CreateOrder(1, []dto.OrderItemInput{{ProductID: product.ID, Quantity: 3}}); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if _, err := inventory.Adjust(product.ID, dto.StockAdjustmentRequest{Delta: -2, Reason: "adjustment"}, 1); err != nil {
+		t.Fatalf("adjust: %v", err)
+	}
+	result, err := inventory.Reconcile(product.ID)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if !result.Consistent || len(result.Mismatches) != 0 {
+		t.Fatalf("reconcile = %+v, want consistent", result)
+	}
+
+	// stok yang diubah tanpa lewat ledger harus terdeteksi di produk maupun gudangnya
+	if err := db.Model(&models.Product{}).Where("id = ?", product.ID).Update("stock", 99).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&models.StockLevel{}).Where("product_id = ? AND warehouse_id = ?", product.ID, warehouse.ID).
+		Update("quantity", 99).Error; err != nil {
+		t.Fatal(err)
+	}
+	result, err = inventory.Reconcile(product.ID)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if result.Consistent || len(result.Mismatches) != 2 {
+		t.Fatalf("reconcile = %+v, want product and warehouse mismatch", result)
+	}
+	for _, m := range result.Mismatches {
+		if m.ProductID != product.ID || m.Stock != 99 || m.LedgerStock != 5 {
+			t.Errorf("mismatch = %+v, want product %d stock 99 ledger 5", m, product.ID)
+		}
+	}
+	if result.Mismatches[0].WarehouseID != nil || result.Mismatches[1].WarehouseID == nil || *result.Mismatches[1].WarehouseID != warehouse.ID {
+		t.Errorf("mismatches = %+v, want product then warehouse %d", result.Mismatches, warehouse.ID)
+	}
+}
+
+// TestAdjustConcurrentWithSales menguji penyesuaian relatif yang berjalan bersamaan dengan
+// penjualan; row lock stok hanya berarti di MySQL
+func TestAdjustConcurrentWithSales(t *testing.T) {
+	requireMySQL(t)
+	const (
+		stock    = 10
+		buyers   = 10
+		restocks = 10
+	)
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, stock)
+	inventory := NewInventoryService(repository.NewInventoryRepository(db), repository.NewProductRepository(db),
+		repository.NewWarehouseRepository(db))
+	orders := NewOrderService(repository.NewOrderRepository(db), repository.NewInventoryRepository(db),
+		repository.NewReservationRepository(db), config.OrderConfig{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(userID uint) {
+			defer wg.Done()
+			if _, err := orders.CreateOrder(userID, []dto.OrderItemInput{{ProductID: product.ID, Quantity: 1}}); err != nil {
+				t.Errorf("user %d: create order: %v", userID, err)
+			}
+		}(uint(3_000_000 + int(product.ID)*buyers + i))
+	}
+	for i := 0; i < restocks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := inventory.Adjust(product.ID, dto.StockAdjustmentRequest{Delta: 2, Reason: "restock"}, 1); err != nil {
+				t.Errorf("adjust: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := stock - buyers + 2*restocks
+	var reloaded models.Product
+	if err := db.First(&reloaded, product.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Stock != want {
+		t.Errorf("product stock = %d, want %d", reloaded.Stock, want)
+	}
+	result, err := inventory.Reconcile(product.ID)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if !result.Consistent {
+		t.Errorf("reconcile = %+v, want consistent", result)
+	}
+}
+
+func TestUpdateWithoutStockKeepsConcurrentSale(t *testing.T) {
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, 10)
+	products := NewProductService(repository.NewProductRepository(db), repository.NewInventoryRepository(db),
+		repository.NewCategoryRepository(db), repository.NewTagRepository(db))
+	orders := NewOrderService(repository.NewOrderRepository(db), repository.NewInventoryRepository(db),
+		repository.NewReservationRepository(db), config.OrderConfig{})
+
+	// admin membaca produk, lalu terjadi penjualan sebelum perubahan harganya disimpan
+	edited, err := products.FindByID(product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := orders.CreateOrder(1, []dto.OrderItemInput{{ProductID: product.ID, Quantity: 3}}); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	edited.Price = models.NewMoney(2500)
+	if err := products.Update(edited, edited.Version, nil, nil, nil, 1); err != nil {
+		t.Fatalf("update price: %v", err)
+	}
+
+	var reloaded models.Product
+	if err := db.First(&reloaded, product.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Stock != 7 || reloaded.Price != models.NewMoney(2500) {
+		t.Errorf("after update stock = %d price = %v, want 7 and 25.00", reloaded.Stock, reloaded.Price)
+	}
+}
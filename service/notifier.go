@@ -0,0 +1,95 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/config"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+// Notifier mengirim alert low-stock ke tujuan tertentu. Error membuat event dicoba
+// ulang pada putaran dispatcher berikutnya.
+type Notifier interface {
+	Notify(ctx context.Context, event models.LowStockEvent) error
+}
+
+// NewNotifier memilih notifier berdasarkan konfigurasi; nilai yang tidak dikenal memakai LogNotifier
+func NewNotifier(cfg config.AlertConfig, alertRepo *repository.AlertRepository) Notifier {
+	switch cfg.Notifier {
+	case "webhook":
+		return &WebhookNotifier{URL: cfg.WebhookURL, Client: &http.Client{Timeout: 10 * time.Second}}
+	case "email":
+		return &EmailOutboxNotifier{repo: alertRepo, To: cfg.EmailTo}
+	default:
+		return LogNotifier{}
+	}
+}
+
+func lowStockMessage(event models.LowStockEvent) string {
+	return fmt.Sprintf("Low stock: %s (product #%d) has %d left, reorder threshold %d",
+		event.ProductName, event.ProductID, event.Stock, event.Threshold)
+}
+
+// LogNotifier menulis alert ke log aplikasi
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, event models.LowStockEvent) error {
+	log.Println(lowStockMessage(event))
+	return nil
+}
+
+// WebhookNotifier mengirim alert sebagai JSON lewat HTTP POST
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, event models.LowStockEvent) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":        "product.low_stock",
+		"product_id":   event.ProductID,
+		"product_name": event.ProductName,
+		"stock":        event.Stock,
+		"threshold":    event.Threshold,
+		"created_at":   event.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailOutboxNotifier menyimpan alert sebagai email di tabel outbox untuk dikirim
+// oleh proses pengirim email
+type EmailOutboxNotifier struct {
+	repo *repository.AlertRepository
+	To   string
+}
+
+func (n *EmailOutboxNotifier) Notify(_ context.Context, event models.LowStockEvent) error {
+	return n.repo.CreateEmail(&models.EmailOutbox{
+		To:      n.To,
+		Subject: "Low stock: " + event.ProductName,
+		Body:    lowStockMessage(event),
+	})
+}
@@ -2,52 +2,156 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/wahyuutomoputra/order-management/config"
 	"github.com/wahyuutomoputra/order-management/dto"
 	"github.com/wahyuutomoputra/order-management/models"
 	"github.com/wahyuutomoputra/order-management/repository"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrInvalidOrderStatus     = errors.New("invalid order status")
+	ErrInvalidOrderTransition = errors.New("invalid order status transition")
+	ErrOrderNotCancellable    = errors.New("order can no longer be cancelled")
+	ErrInvalidOrderFilter     = errors.New("invalid order filter")
+	// ErrOrderStockNotRestorable dikembalikan saat order dibatalkan tetapi produk atau
+	// varian salah satu itemnya sudah dihapus sehingga stoknya tidak bisa dikembalikan
+	ErrOrderStockNotRestorable = errors.New("order stock cannot be restored because a product or variant no longer exists")
+)
+
+// orderSortColumns memetakan parameter sort ke kolom tabel orders
+var orderSortColumns = map[string]string{
+	"id":          "id",
+	"created_at":  "created_at",
+	"grand_total": "grand_total",
+}
+
 type OrderService struct {
-	repo *repository.OrderRepository
+	repo         *repository.OrderRepository
+	inventory    *repository.InventoryRepository
+	reservations *repository.ReservationRepository
+	allocator    AllocationStrategy
+	cancelCutoff models.OrderStatus
+	taxRate      float64
+	shippingFee  models.Money
+}
+
+func NewOrderService(repo *repository.OrderRepository, inventory *repository.InventoryRepository, reservations *repository.ReservationRepository, cfg config.OrderConfig) *OrderService {
+	cutoff := models.OrderStatus(cfg.CancelCutoff)
+	if !cutoff.Valid() || cutoff.IsAfter(models.OrderStatusPacked) {
+		cutoff = models.OrderStatusPending
+	}
+	shippingFee, err := models.ParseMoney(cfg.ShippingFee)
+	if err != nil {
+		shippingFee = models.NewMoney(0)
+	}
+	return &OrderService{
+		repo:         repo,
+		inventory:    inventory,
+		reservations: reservations,
+		allocator:    NewAllocationStrategy(cfg.AllocationStrategy),
+		cancelCutoff: cutoff,
+		taxRate:      cfg.TaxRate,
+		shippingFee:  shippingFee,
+	}
 }
 
-func NewOrderService(repo *repository.OrderRepository) *OrderService {
-	return &OrderService{repo}
+// SetAllocationStrategy mengganti strategi pemilihan gudang untuk order berikutnya
+func (s *OrderService) SetAllocationStrategy(strategy AllocationStrategy) {
+	s.allocator = strategy
 }
 
 func (s *OrderService) CreateOrder(userID uint, items []dto.OrderItemInput) (*models.Order, error) {
+	return s.CreateOrderWith(userID, items, nil)
+}
+
+// CreateOrderWith membuat order seperti CreateOrder lalu menjalankan then di dalam
+// transaksi yang sama, sehingga perubahan lain (mis. mengosongkan keranjang) ikut
+// di-rollback jika order gagal dibuat.
+func (s *OrderService) CreateOrderWith(userID uint, items []dto.OrderItemInput, then func(tx *gorm.DB, order *models.Order) error) (*models.Order, error) {
+	return s.CreateOrderFrom(userID, func(*gorm.DB) ([]dto.OrderItemInput, error) {
+		return items, nil
+	}, then)
+}
+
+// CreateOrderFrom sama seperti CreateOrderWith, tetapi item order dibaca oleh load di
+// dalam transaksi order sebelum stok dialokasikan. load dipanggil ulang setiap kali
+// transaksi diulang, sehingga sumber item (mis. keranjang) bisa dikunci dan dibaca
+// konsisten dengan perubahan yang dilakukan then.
+func (s *OrderService) CreateOrderFrom(userID uint, load func(tx *gorm.DB) ([]dto.OrderItemInput, error), then func(tx *gorm.DB, order *models.Order) error) (*models.Order, error) {
 	var resultOrder *models.Order
-	err := s.repo.DB().Transaction(func(tx *gorm.DB) error {
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		items, err := load(tx)
+		if err != nil {
+			return err
+		}
+		items = mergeOrderItems(items)
 		repoTx := s.repo.WithTx(tx)
+		inventoryTx := s.inventory.WithTx(tx)
+		reservationsTx := s.reservations.WithTx(tx)
 		order := models.Order{
 			UserID:    userID,
+			Status:    models.OrderStatusPending,
 			CreatedAt: int64(0), // set di handler
 		}
+		// header order dibuat lebih dulu agar movement stok bisa merujuk ID order
+		if err := repoTx.Create(&order); err != nil {
+			return err
+		}
+		// stok gudang semua item dikunci lebih dulu, baru varian dan produk diubah lewat
+		// Apply, sehingga urutan lock selalu stock_levels → product_variants → products.
+		// items sudah terurut berdasarkan product ID lalu variant ID sehingga row lock
+		// selalu diambil dengan urutan yang sama dan tidak saling deadlock.
+		now := time.Now().Unix()
+		available := make([][]models.StockLevel, len(items))
+		for i, item := range items {
+			levels, err := lockAvailableLevels(inventoryTx, reservationsTx, item.ProductID, item.VariantID, userID, now)
+			if err != nil {
+				return err
+			}
+			available[i] = levels
+		}
 		var orderItems []models.OrderItem
-		for _, item := range items {
-			product, err := repoTx.FindProductByID(item.ProductID)
+		for i, item := range items {
+			allocated, err := s.deductItemStock(repoTx, inventoryTx, order.ID, userID, item, available[i])
 			if err != nil {
-				return errors.New("product not found")
+				return err
 			}
-			if product.Stock < item.Quantity {
-				return errors.New("Insufficient stock for product: " + product.Name)
+			for _, orderItem := range allocated {
+				orderItem.OrderID = order.ID
+				orderItems = append(orderItems, orderItem)
 			}
-			product.Stock -= item.Quantity
-			if err := repoTx.UpdateProduct(product); err != nil {
+			// reservasi customer untuk item ini sudah terpakai menjadi pengurangan stok
+			if err := reservationsTx.MarkConverted(userID, item.ProductID, item.VariantID, order.ID); err != nil {
 				return err
 			}
-			orderItems = append(orderItems, models.OrderItem{
-				ProductID: product.ID,
-				Quantity:  item.Quantity,
-				Price:     product.Price,
-			})
+		}
+		if err := repoTx.CreateItems(orderItems); err != nil {
+			return err
 		}
 		order.Items = orderItems
-		if err := repoTx.Create(&order); err != nil {
+		s.applyTotals(&order)
+		if err := repoTx.UpdateTotals(&order); err != nil {
 			return err
 		}
+		if err := repoTx.CreateStatusHistory(&models.OrderStatusHistory{
+			OrderID:   order.ID,
+			ToStatus:  order.Status,
+			ChangedBy: userID,
+		}); err != nil {
+			return err
+		}
+		if then != nil {
+			if err := then(tx, &order); err != nil {
+				return err
+			}
+		}
 		resultOrder = &order
 		return nil
 	})
@@ -57,6 +161,342 @@ func (s *OrderService) CreateOrder(userID uint, items []dto.OrderItemInput) (*mo
 	return resultOrder, nil
 }
 
+// deductItemStock mengalokasikan satu item ke gudang lewat strategi alokasi, mengurangi
+// stok tiap gudang lewat ledger inventori dan mengembalikan satu OrderItem per gudang
+// dengan harga saat ini. levels adalah stok gudang yang sudah dikunci lockAvailableLevels,
+// sehingga stok yang ditahan reservasi customer lain tidak ikut dialokasikan.
+func (s *OrderService) deductItemStock(repoTx *repository.OrderRepository, inventoryTx *repository.InventoryRepository, orderID, userID uint, item dto.OrderItemInput, levels []models.StockLevel) ([]models.OrderItem, error) {
+	product, err := repoTx.FindProductByID(item.ProductID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	template := models.OrderItem{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		ProductSKU:  productSKU(product),
+		Price:       product.Price,
+	}
+	label := product.Name
+	if item.VariantID == 0 {
+		variants, err := repoTx.CountVariants(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if variants > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrVariantRequired, product.Name)
+		}
+	} else {
+		variant, err := repoTx.FindVariantByID(item.ProductID, item.VariantID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrVariantNotFound
+			}
+			return nil, err
+		}
+		template.VariantID = &variant.ID
+		template.VariantSKU = variant.SKU
+		template.VariantOptions = variant.Options
+		template.Price = variant.EffectivePrice(*product)
+		label += " (" + variant.SKU + ")"
+	}
+
+	allocations, ok := s.allocator.Allocate(levels, item.Quantity)
+	if !ok {
+		return nil, fmt.Errorf("%w for product: %s", ErrInsufficientStock, label)
+	}
+	orderItems := make([]models.OrderItem, 0, len(allocations))
+	for _, allocation := range allocations {
+		ok, err := inventoryTx.Apply(&models.InventoryMovement{
+			ProductID:   item.ProductID,
+			VariantID:   template.VariantID,
+			WarehouseID: allocation.WarehouseID,
+			Delta:       -allocation.Quantity,
+			Reason:      models.MovementSale,
+			ReferenceID: orderID,
+			ActorID:     userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w for product: %s", ErrInsufficientStock, label)
+		}
+		orderItem := template
+		orderItem.WarehouseID = allocation.WarehouseID
+		orderItem.Quantity = allocation.Quantity
+		orderItem.LineTotal = template.Price.Mul(allocation.Quantity)
+		orderItems = append(orderItems, orderItem)
+	}
+	return orderItems, nil
+}
+
+func productSKU(product *models.Product) string {
+	if product.SKU == nil {
+		return ""
+	}
+	return *product.SKU
+}
+
+// applyTotals menghitung subtotal, pajak, ongkir dan grand total dari item order
+func (s *OrderService) applyTotals(order *models.Order) {
+	subtotal := models.NewMoney(0)
+	for _, item := range order.Items {
+		subtotal = subtotal.Add(item.LineTotal)
+	}
+	order.Subtotal = subtotal
+	order.Tax = subtotal.Sub(order.Discount).MulRate(s.taxRate)
+	order.ShippingFee = s.shippingFee
+	order.GrandTotal = subtotal.Sub(order.Discount).Add(order.Tax).Add(order.ShippingFee)
+}
+
+// mergeOrderItems menggabungkan item dengan produk dan varian yang sama lalu
+// mengurutkannya berdasarkan product ID dan variant ID
+func mergeOrderItems(items []dto.OrderItemInput) []dto.OrderItemInput {
+	type lineKey struct{ productID, variantID uint }
+	index := make(map[lineKey]int, len(items))
+	var merged []dto.OrderItemInput
+	for _, item := range items {
+		key := lineKey{item.ProductID, item.VariantID}
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, item)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].ProductID != merged[j].ProductID {
+			return merged[i].ProductID < merged[j].ProductID
+		}
+		return merged[i].VariantID < merged[j].VariantID
+	})
+	return merged
+}
+
+// GetOrder mengambil satu order. Order milik customer lain dianggap tidak ada
+// agar ID order orang lain tidak bisa ditebak; admin bisa mengambil order apa pun.
+func (s *OrderService) GetOrder(orderID, userID uint, isAdmin bool) (*models.Order, error) {
+	order, err := s.repo.FindByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if !isAdmin && order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func variantIDOf(item models.OrderItem) uint {
+	if item.VariantID == nil {
+		return 0
+	}
+	return *item.VariantID
+}
+
+// ListOrders mengembalikan daftar order untuk admin sesuai filter dan pagination
+func (s *OrderService) ListOrders(filter dto.OrderFilter) ([]models.Order, dto.PageMeta, error) {
+	filter.Normalize()
+	q := repository.OrderQuery{
+		UserID:    filter.UserID,
+		Status:    models.OrderStatus(filter.Status),
+		ProductID: filter.ProductID,
+		SortBy:    "created_at",
+		SortDesc:  true,
+		Offset:    filter.Offset(),
+		Limit:     filter.Limit,
+	}
+	if filter.Sort != "" {
+		q.SortDesc = strings.HasPrefix(filter.Sort, "-")
+		q.SortBy = orderSortColumns[strings.TrimPrefix(filter.Sort, "-")]
+	}
+	var err error
+	if filter.From != "" {
+		if q.From, err = parseFilterTime(filter.From, false); err != nil {
+			return nil, dto.PageMeta{}, fmt.Errorf("%w: from", ErrInvalidOrderFilter)
+		}
+	}
+	if filter.To != "" {
+		if q.To, err = parseFilterTime(filter.To, true); err != nil {
+			return nil, dto.PageMeta{}, fmt.Errorf("%w: to", ErrInvalidOrderFilter)
+		}
+	}
+	if filter.MinTotal != "" {
+		m, err := models.ParseMoney(filter.MinTotal)
+		if err != nil {
+			return nil, dto.PageMeta{}, fmt.Errorf("%w: min_total", ErrInvalidOrderFilter)
+		}
+		q.MinTotal = &m.Amount
+	}
+	if filter.MaxTotal != "" {
+		m, err := models.ParseMoney(filter.MaxTotal)
+		if err != nil {
+			return nil, dto.PageMeta{}, fmt.Errorf("%w: max_total", ErrInvalidOrderFilter)
+		}
+		q.MaxTotal = &m.Amount
+	}
+
+	orders, total, err := s.repo.FindAll(q)
+	if err != nil {
+		return nil, dto.PageMeta{}, err
+	}
+	return orders, dto.NewPageMeta(filter.PageQuery, total), nil
+}
+
+// parseFilterTime menerima tanggal (2006-01-02) atau RFC3339. Untuk batas akhir,
+// tanggal tanpa jam dianggap sampai akhir hari tersebut.
+func parseFilterTime(value string, endOfDay bool) (int64, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Unix(), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return 0, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return t.Unix(), nil
+}
+
 func (s *OrderService) GetOrderHistory(userID uint) ([]models.Order, error) {
 	return s.repo.FindByUser(userID)
 }
+
+// UpdateStatus memindahkan order ke status baru sesuai state machine dan mencatat riwayatnya
+func (s *OrderService) UpdateStatus(orderID uint, to models.OrderStatus, actorID uint, note string) (*models.Order, error) {
+	if !to.Valid() {
+		return nil, ErrInvalidOrderStatus
+	}
+	var resultOrder *models.Order
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		order, err := repoTx.FindByIDForUpdate(orderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+		if err := s.transition(repoTx, order, to, actorID, note); err != nil {
+			return err
+		}
+		resultOrder = order
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultOrder, nil
+}
+
+// CancelOrder membatalkan order dan mengembalikan stok setiap item dalam satu transaksi.
+// Customer hanya bisa membatalkan order miliknya sampai batas status cancelCutoff,
+// sedangkan admin bisa membatalkan order apa pun selama state machine mengizinkan.
+func (s *OrderService) CancelOrder(orderID, userID uint, isAdmin bool, reason string) (*models.Order, error) {
+	var resultOrder *models.Order
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		order, err := repoTx.FindByIDForUpdate(orderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+		if !isAdmin {
+			// order milik customer lain diperlakukan seperti tidak ada
+			if order.UserID != userID {
+				return ErrOrderNotFound
+			}
+			if order.Status.IsAfter(s.cancelCutoff) {
+				return ErrOrderNotCancellable
+			}
+		}
+		if !order.Status.CanTransitionTo(models.OrderStatusCancelled) {
+			return ErrOrderNotCancellable
+		}
+		if err := s.transition(repoTx, order, models.OrderStatusCancelled, userID, reason); err != nil {
+			return err
+		}
+		resultOrder = order
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultOrder, nil
+}
+
+// transition memvalidasi dan menyimpan perpindahan status, harus dipanggil di dalam transaksi
+func (s *OrderService) transition(repoTx *repository.OrderRepository, order *models.Order, to models.OrderStatus, actorID uint, note string) error {
+	if !order.Status.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidOrderTransition, order.Status, to)
+	}
+	from := order.Status
+	if err := repoTx.UpdateStatus(order, to); err != nil {
+		return err
+	}
+	if to == models.OrderStatusCancelled {
+		items := append([]models.OrderItem(nil), order.Items...)
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].ProductID != items[j].ProductID {
+				return items[i].ProductID < items[j].ProductID
+			}
+			return variantIDOf(items[i]) < variantIDOf(items[j])
+		})
+		inventoryTx := s.inventory.WithTx(repoTx.DB())
+		// stok gudang dikunci lebih dulu seperti checkout, baru stok dikembalikan
+		for i, item := range items {
+			if i > 0 && item.ProductID == items[i-1].ProductID && variantIDOf(item) == variantIDOf(items[i-1]) {
+				continue
+			}
+			if _, err := inventoryTx.LockLevels(item.ProductID, variantIDOf(item)); err != nil {
+				return err
+			}
+		}
+		for _, item := range items {
+			ok, err := inventoryTx.Apply(&models.InventoryMovement{
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				WarehouseID: item.WarehouseID,
+				Delta:       item.Quantity,
+				Reason:      models.MovementCancel,
+				ReferenceID: order.ID,
+				ActorID:     actorID,
+				Note:        note,
+			})
+			// produk/varian yang sudah dihapus membatalkan seluruh transaksi, sehingga stok
+			// gudang yang terlanjur bertambah ikut di-rollback dan tetap sama dengan ledger
+			if errors.Is(err, ErrProductNotFound) || errors.Is(err, ErrVariantNotFound) || err == nil && !ok {
+				return fmt.Errorf("%w: product %d", ErrOrderStockNotRestorable, item.ProductID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	order.Status = to
+	return repoTx.CreateStatusHistory(&models.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedBy:  actorID,
+		Note:       note,
+	})
+}
+
+func (s *OrderService) GetStatusHistory(orderID uint) ([]models.OrderStatusHistory, error) {
+	if _, err := s.repo.FindByID(orderID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return s.repo.FindStatusHistory(orderID)
+}
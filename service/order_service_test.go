@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/wahyuutomoputra/order-management/config"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+// TestCreateOrderConcurrentStock menguji urutan row lock dan retry deadlock checkout,
+// sehingga hanya berarti di MySQL
+func TestCreateOrderConcurrentStock(t *testing.T) {
+	requireMySQL(t)
+	const (
+		stock  = 5
+		buyers = 20
+	)
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, stock)
+	orders := NewOrderService(
+		repository.NewOrderRepository(db),
+		repository.NewInventoryRepository(db),
+		repository.NewReservationRepository(db),
+		config.OrderConfig{},
+	)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(userID uint) {
+			defer wg.Done()
+			_, err := orders.CreateOrder(userID, []dto.OrderItemInput{{ProductID: product.ID, Quantity: 1}})
+			if err != nil && !errors.Is(err, ErrInsufficientStock) {
+				t.Errorf("user %d: unexpected error: %v", userID, err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}(uint(i + 1))
+	}
+	wg.Wait()
+
+	if succeeded != stock {
+		t.Errorf("succeeded orders = %d, want %d", succeeded, stock)
+	}
+	var reloaded models.Product
+	if err := db.First(&reloaded, product.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Stock != 0 {
+		t.Errorf("product stock = %d, want 0", reloaded.Stock)
+	}
+	var level models.StockLevel
+	if err := db.Where("product_id = ? AND warehouse_id = ?", product.ID, warehouse.ID).First(&level).Error; err != nil {
+		t.Fatal(err)
+	}
+	if level.Quantity != 0 {
+		t.Errorf("stock level = %d, want 0", level.Quantity)
+	}
+	var ledger struct {
+		Total int
+		Sales int64
+	}
+	if err := db.Model(&models.InventoryMovement{}).Where("product_id = ?", product.ID).
+		Select("COALESCE(SUM(delta), 0) AS total, SUM(CASE WHEN reason = ? THEN 1 ELSE 0 END) AS sales", models.MovementSale).
+		Scan(&ledger).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Total != reloaded.Stock {
+		t.Errorf("ledger sum = %d, want product stock %d", ledger.Total, reloaded.Stock)
+	}
+	if ledger.Sales != stock {
+		t.Errorf("sale movements = %d, want %d", ledger.Sales, stock)
+	}
+	var sold int64
+	if err := db.Model(&models.OrderItem{}).Where("product_id = ?", product.ID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&sold).Error; err != nil {
+		t.Fatal(err)
+	}
+	if sold != stock {
+		t.Errorf("ordered quantity = %d, want %d", sold, stock)
+	}
+}
+
+func TestCancelOrderWithDeletedVariant(t *testing.T) {
+	db := openTestDB(t)
+	seedWarehouse(t, db, 0)
+	product := models.Product{Name: "Test " + t.Name(), Price: models.NewMoney(1000)}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	productRepo := repository.NewProductRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	products := NewProductService(productRepo, inventoryRepo, repository.NewCategoryRepository(db), repository.NewTagRepository(db))
+	orders := NewOrderService(repository.NewOrderRepository(db), inventoryRepo, repository.NewReservationRepository(db), config.OrderConfig{})
+
+	stock := 3
+	variant, err := products.CreateVariant(product.ID, dto.VariantRequest{SKU: "VAR-" + t.Name(), Stock: &stock}, 1)
+	if err != nil {
+		t.Fatalf("create variant: %v", err)
+	}
+	order, err := orders.CreateOrder(1, []dto.OrderItemInput{{ProductID: product.ID, VariantID: variant.ID, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if err := products.DeleteVariant(product.ID, variant.ID, 1); err != nil {
+		t.Fatalf("delete variant: %v", err)
+	}
+
+	if _, err := orders.CancelOrder(order.ID, 1, true, ""); !errors.Is(err, ErrOrderStockNotRestorable) {
+		t.Fatalf("cancel order: error = %v, want ErrOrderStockNotRestorable", err)
+	}
+	var reloaded models.Order
+	if err := db.First(&reloaded, order.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Status == models.OrderStatusCancelled {
+		t.Errorf("order status = %s, want unchanged", reloaded.Status)
+	}
+	// pembatalan yang gagal tidak boleh meninggalkan stok gudang tanpa movement
+	mismatches, err := inventoryRepo.FindMismatches(product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) > 0 {
+		t.Errorf("stock mismatches after failed cancel: %+v", mismatches)
+	}
+}
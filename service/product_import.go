@@ -0,0 +1,419 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+
+	// maxImportRows membatasi jumlah baris per file agar transaksi import tidak terlalu besar
+	maxImportRows   = 5000
+	exportBatchSize = 500
+
+	importRowSavepointName = "import_row"
+)
+
+var (
+	ErrInvalidImportFile = errors.New("invalid import file")
+	ErrImportTooLarge    = fmt.Errorf("import file exceeds %d rows", maxImportRows)
+
+	// errImportRollback membatalkan transaksi import saat dry run atau ada baris yang error
+	errImportRollback = errors.New("import rolled back")
+)
+
+// productImportColumns adalah kolom CSV import/ekspor, urutannya dipakai saat ekspor
+var productImportColumns = []string{"sku", "name", "price", "stock", "reorder_threshold", "category_ids", "tags"}
+
+// importValidate memvalidasi baris import dengan aturan yang sama seperti request API produk
+var importValidate = utils.NewValidator()
+
+// importRowError adalah kesalahan isi satu baris import, misal nilai yang tidak valid
+type importRowError string
+
+func (e importRowError) Error() string {
+	return string(e)
+}
+
+// isImportRowError melaporkan apakah err disebabkan isi baris sehingga dilaporkan per
+// baris. Error lain (misal deadlock atau koneksi database) membatalkan transaksi import
+// agar diulang runInTx atau dikembalikan ke pemanggil.
+func isImportRowError(err error) bool {
+	var rowErr importRowError
+	return errors.As(err, &rowErr) ||
+		errors.Is(err, ErrProductSKUTaken) ||
+		errors.Is(err, ErrCategoryNotFound) ||
+		errors.Is(err, ErrInsufficientStock) ||
+		errors.Is(err, ErrStockManagedByVariants) ||
+		errors.Is(err, ErrStockInMultipleWarehouses) ||
+		errors.Is(err, ErrNoActiveWarehouse)
+}
+
+// importRow adalah baris file import beserta nomor barisnya di file
+type importRow struct {
+	line int
+	data dto.ProductImportRow
+	err  error
+}
+
+// Import membuat atau mengubah produk berdasarkan SKU dari file CSV/JSONL dalam satu
+// transaksi. Jika ada baris yang isinya error, tidak ada perubahan yang disimpan dan semua
+// error dilaporkan per baris; error database membatalkan import dan dikembalikan.
+// Dry run menjalankan proses yang sama lalu membatalkannya.
+func (s *ProductService) Import(r io.Reader, format string, dryRun bool, actorID uint) (*dto.ImportReport, error) {
+	rows, err := parseImportRows(r, format)
+	if err != nil {
+		return nil, err
+	}
+	var report *dto.ImportReport
+	err = runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		report = &dto.ImportReport{DryRun: dryRun, Total: len(rows), Errors: []dto.ImportRowError{}}
+		for _, row := range rows {
+			err := row.err
+			created := false
+			if err == nil {
+				created, err = s.importRowSavepoint(tx, row.data, actorID)
+				if err != nil && !isImportRowError(err) {
+					return err
+				}
+			}
+			switch {
+			case err != nil:
+				report.Errors = append(report.Errors, dto.ImportRowError{Row: row.line, SKU: row.data.SKU, Error: err.Error()})
+			case created:
+				report.Created++
+			default:
+				report.Updated++
+			}
+		}
+		if dryRun || len(report.Errors) > 0 {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, err
+	}
+	report.Committed = err == nil
+	return report, nil
+}
+
+// importRowSavepoint menjalankan importRow di dalam savepoint. Jika barisnya error,
+// perubahan yang sudah sempat ditulis (misal produk yang sudah di-insert sebelum stoknya
+// gagal disimpan) dibatalkan sehingga tidak terlihat oleh baris berikutnya.
+func (s *ProductService) importRowSavepoint(tx *gorm.DB, row dto.ProductImportRow, actorID uint) (bool, error) {
+	if err := tx.SavePoint(importRowSavepointName).Error; err != nil {
+		return false, err
+	}
+	created, err := s.importRow(tx, row, actorID)
+	if err != nil && isImportRowError(err) {
+		if rbErr := tx.RollbackTo(importRowSavepointName).Error; rbErr != nil {
+			return false, rbErr
+		}
+	}
+	return created, err
+}
+
+// importRow menerapkan satu baris import, mengembalikan true jika produk baru dibuat
+func (s *ProductService) importRow(tx *gorm.DB, row dto.ProductImportRow, actorID uint) (bool, error) {
+	if row.SKU == "" {
+		return false, importRowError("sku is required")
+	}
+	var price *models.Money
+	if row.Price != "" {
+		m, err := models.ParseMoney(row.Price)
+		if err != nil {
+			return false, importRowError("price must be a decimal amount")
+		}
+		price = &m
+	}
+	// baris divalidasi dengan aturan yang sama seperti PATCH produk: sel kosong berarti
+	// tidak diubah, sel yang terisi harus valid
+	sku := row.SKU
+	patch := dto.ProductPatchRequest{
+		SKU:              &sku,
+		Price:            price,
+		Stock:            row.Stock,
+		ReorderThreshold: row.ReorderThreshold,
+		CategoryIDs:      row.CategoryIDs,
+		Tags:             row.Tags,
+	}
+	if row.Name != "" {
+		patch.Name = &row.Name
+	}
+	if err := importValidate.Struct(patch); err != nil {
+		return false, importRowError(err.Error())
+	}
+	categoryIDs := row.CategoryIDs
+	if len(categoryIDs) == 0 {
+		categoryIDs = nil
+	}
+	tagNames := row.Tags
+	if len(tagNames) == 0 {
+		tagNames = nil
+	}
+
+	product, err := s.repo.WithTx(tx).FindBySKU(row.SKU)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if row.Name == "" || price == nil {
+			return false, importRowError("name and price are required for a new product")
+		}
+		product = &models.Product{Name: row.Name, SKU: &sku, Price: *price}
+		if row.Stock != nil {
+			product.Stock = *row.Stock
+		}
+		if row.ReorderThreshold != nil {
+			product.ReorderThreshold = *row.ReorderThreshold
+		}
+		return true, s.createTx(tx, product, categoryIDs, tagNames, actorID)
+	}
+	if err != nil {
+		return false, err
+	}
+	if product.DeletedAt.Valid {
+		return false, importRowError("product is archived")
+	}
+	if row.Name != "" {
+		product.Name = row.Name
+	}
+	if price != nil {
+		product.Price = *price
+	}
+	if row.ReorderThreshold != nil {
+		product.ReorderThreshold = *row.ReorderThreshold
+	}
+	return false, s.updateTx(tx, product, product.Version, categoryIDs, tagNames, row.Stock, actorID)
+}
+
+// parseImportRows membaca seluruh baris file import. Error format file secara keseluruhan
+// dikembalikan langsung, error pada satu baris disimpan di baris tersebut.
+func parseImportRows(r io.Reader, format string) ([]importRow, error) {
+	switch format {
+	case ImportFormatCSV:
+		return parseImportCSV(r)
+	case ImportFormatJSONL:
+		return parseImportJSONL(r)
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImportFile, format)
+}
+
+func parseImportCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing CSV header", ErrInvalidImportFile)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !isImportColumn(name) {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImportFile, name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["sku"]; !ok {
+		return nil, fmt.Errorf("%w: column sku is required", ErrInvalidImportFile)
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, ErrImportTooLarge
+		}
+		line, _ := reader.FieldPos(0)
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := importRow{line: line}
+		row.data, row.err = csvImportRow(cell)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// csvImportRow mengubah sel CSV menjadi ProductImportRow; sel kosong berarti tidak diubah
+func csvImportRow(cell func(name string) string) (dto.ProductImportRow, error) {
+	row := dto.ProductImportRow{SKU: cell("sku"), Name: cell("name"), Price: cell("price")}
+	var err error
+	if row.Stock, err = parseOptionalInt(cell("stock")); err != nil {
+		return row, errors.New("stock must be an integer")
+	}
+	if row.ReorderThreshold, err = parseOptionalInt(cell("reorder_threshold")); err != nil {
+		return row, errors.New("reorder_threshold must be an integer")
+	}
+	for _, value := range splitImportList(cell("category_ids")) {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			return row, errors.New("category_ids must be a |-separated list of ids")
+		}
+		row.CategoryIDs = append(row.CategoryIDs, uint(id))
+	}
+	row.Tags = splitImportList(cell("tags"))
+	return row, nil
+}
+
+func parseImportJSONL(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, ErrImportTooLarge
+		}
+		row := importRow{line: line}
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.data); err != nil {
+			row.err = fmt.Errorf("invalid JSON: %v", err)
+		}
+		row.data.SKU = strings.TrimSpace(row.data.SKU)
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	return rows, nil
+}
+
+func isImportColumn(name string) bool {
+	for _, column := range productImportColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseOptionalInt(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// splitImportList memecah sel CSV berisi daftar yang dipisahkan "|"
+func splitImportList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Export menulis seluruh produk aktif ke w dalam format CSV atau JSONL per batch, dengan
+// kolom yang sama seperti file import sehingga hasil ekspor bisa diimpor kembali.
+// Produk tanpa SKU dilewati karena import mencocokkan produk berdasarkan SKU; jumlahnya
+// bisa diambil lewat CountExportSkipped.
+func (s *ProductService) Export(w io.Writer, format string) error {
+	var write func(row dto.ProductImportRow) error
+	var flush func() error
+	switch format {
+	case ImportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(productImportColumns); err != nil {
+			return err
+		}
+		write = func(row dto.ProductImportRow) error { return writer.Write(csvExportRecord(row)) }
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case ImportFormatJSONL:
+		encoder := json.NewEncoder(w)
+		write = func(row dto.ProductImportRow) error { return encoder.Encode(row) }
+		flush = func() error { return nil }
+	default:
+		return fmt.Errorf("%w: unsupported format %q", ErrInvalidImportFile, format)
+	}
+
+	return s.repo.FindWithSKUInBatches(exportBatchSize, func(products []models.Product) error {
+		for _, product := range products {
+			if err := write(exportRow(product)); err != nil {
+				return err
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		// kirim batch ke client tanpa menunggu seluruh katalog selesai dibaca
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+// CountExportSkipped menghitung produk aktif yang tidak ikut diekspor karena tidak punya SKU
+func (s *ProductService) CountExportSkipped() (int64, error) {
+	return s.repo.CountWithoutSKU()
+}
+
+func exportRow(product models.Product) dto.ProductImportRow {
+	stock, threshold := product.Stock, product.ReorderThreshold
+	row := dto.ProductImportRow{
+		SKU:              *product.SKU,
+		Name:             product.Name,
+		Price:            product.Price.String(),
+		Stock:            &stock,
+		ReorderThreshold: &threshold,
+	}
+	for _, category := range product.Categories {
+		row.CategoryIDs = append(row.CategoryIDs, category.ID)
+	}
+	for _, tag := range product.Tags {
+		row.Tags = append(row.Tags, tag.Name)
+	}
+	return row
+}
+
+func csvExportRecord(row dto.ProductImportRow) []string {
+	categoryIDs := make([]string, len(row.CategoryIDs))
+	for i, id := range row.CategoryIDs {
+		categoryIDs[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return []string{
+		row.SKU,
+		row.Name,
+		row.Price,
+		strconv.Itoa(*row.Stock),
+		strconv.Itoa(*row.ReorderThreshold),
+		strings.Join(categoryIDs, "|"),
+		strings.Join(row.Tags, "|"),
+	}
+}
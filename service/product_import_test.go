@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+func TestIsImportRowError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{importRowError("sku is required"), true},
+		{fmt.Errorf("row 3: %w", importRowError("product is archived")), true},
+		{ErrProductSKUTaken, true},
+		{fmt.Errorf("%w: 99", ErrCategoryNotFound), true},
+		{ErrInsufficientStock, true},
+		{ErrStockManagedByVariants, true},
+		{ErrNoActiveWarehouse, true},
+		// error database harus membatalkan transaksi agar bisa diulang
+		{errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), false},
+		{errImportRollback, false},
+	}
+	for _, tt := range tests {
+		if got := isImportRowError(tt.err); got != tt.want {
+			t.Errorf("isImportRowError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestProductExportImportRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	products := NewProductService(repository.NewProductRepository(db), repository.NewInventoryRepository(db),
+		repository.NewCategoryRepository(db), repository.NewTagRepository(db))
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	category := models.Category{Name: "Minuman", Slug: "minuman-" + suffix}
+	if err := db.Create(&category).Error; err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	skus := []string{"KOPI-" + suffix, "TEH-" + suffix}
+	want := map[string]models.Product{}
+	for i, sku := range skus {
+		sku := sku
+		product := models.Product{SKU: &sku, Name: "Produk " + sku, Price: models.NewMoney(int64(1250 * (i + 1))), Stock: 4 + i, ReorderThreshold: i}
+		if err := products.Create(&product, []uint{category.ID}, []string{"impor-" + suffix, "rt-" + suffix}, 1); err != nil {
+			t.Fatalf("create %s: %v", sku, err)
+		}
+		want[sku] = product
+	}
+	// produk tanpa SKU tidak bisa diimpor kembali sehingga tidak ikut diekspor
+	withoutSKU := seedProduct(t, db, warehouse, 2)
+
+	skipped, err := products.CountExportSkipped()
+	if err != nil {
+		t.Fatalf("count skipped: %v", err)
+	}
+	if skipped < 1 {
+		t.Errorf("CountExportSkipped() = %d, want at least 1", skipped)
+	}
+
+	for _, format := range []string{ImportFormatCSV, ImportFormatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := products.Export(&buf, format); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			exported := buf.String()
+			for _, sku := range skus {
+				if !strings.Contains(exported, sku) {
+					t.Errorf("export does not contain %s", sku)
+				}
+			}
+			if strings.Contains(exported, withoutSKU.Name) {
+				t.Errorf("export contains product without SKU %q", withoutSKU.Name)
+			}
+
+			report, err := products.Import(strings.NewReader(exported), format, false, 1)
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if len(report.Errors) > 0 {
+				t.Fatalf("import row errors: %+v", report.Errors)
+			}
+			if !report.Committed || report.Created != 0 || report.Updated != report.Total || report.Total < len(skus) {
+				t.Errorf("report = %+v, want committed with every row updated", report)
+			}
+
+			for _, sku := range skus {
+				got, err := repository.NewProductRepository(db).FindBySKU(sku)
+				if err != nil {
+					t.Fatalf("find %s: %v", sku, err)
+				}
+				if err := db.Model(got).Association("Categories").Find(&got.Categories); err != nil {
+					t.Fatal(err)
+				}
+				if err := db.Model(got).Association("Tags").Find(&got.Tags); err != nil {
+					t.Fatal(err)
+				}
+				original := want[sku]
+				if got.Name != original.Name || got.Price != original.Price || got.Stock != original.Stock || got.ReorderThreshold != original.ReorderThreshold {
+					t.Errorf("%s after import = %q %v stock %d threshold %d, want %q %v stock %d threshold %d", sku,
+						got.Name, got.Price, got.Stock, got.ReorderThreshold,
+						original.Name, original.Price, original.Stock, original.ReorderThreshold)
+				}
+				if len(got.Categories) != 1 || got.Categories[0].ID != category.ID {
+					t.Errorf("%s categories = %+v, want [%d]", sku, got.Categories, category.ID)
+				}
+				var tags []string
+				for _, tag := range got.Tags {
+					tags = append(tags, tag.Name)
+				}
+				sort.Strings(tags)
+				if wantTags := []string{"impor-" + suffix, "rt-" + suffix}; !reflect.DeepEqual(tags, wantTags) {
+					t.Errorf("%s tags = %v, want %v", sku, tags, wantTags)
+				}
+			}
+		})
+	}
+}
+
+func TestImportRowValidation(t *testing.T) {
+	db := openTestDB(t)
+	seedWarehouse(t, db, 0)
+	products := newTestProductService(db)
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	manyTags := make([]string, 21)
+	for i := range manyTags {
+		manyTags[i] = fmt.Sprintf("t%d", i)
+	}
+	csv := "sku,name,price,tags\n" +
+		"OK-" + suffix + ",Produk valid,10.00,a|b\n" +
+		"TAGS-" + suffix + ",Terlalu banyak tag,10.00," + strings.Join(manyTags, "|") + "\n" +
+		"LONGTAG-" + suffix + ",Tag terlalu panjang,10.00," + strings.Repeat("x", 51) + "\n" +
+		"NAME-" + suffix + "," + strings.Repeat("n", 256) + ",10.00,\n" +
+		"PRICE-" + suffix + ",Harga nol,0,\n"
+	report, err := products.Import(strings.NewReader(csv), ImportFormatCSV, false, 1)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Committed {
+		t.Error("import with invalid rows was committed")
+	}
+	var failed []int
+	for _, rowErr := range report.Errors {
+		failed = append(failed, rowErr.Row)
+	}
+	if want := []int{3, 4, 5, 6}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("failed rows = %v (%+v), want %v", failed, report.Errors, want)
+	}
+}
+
+func TestImportFailedRowLeavesNoPartialState(t *testing.T) {
+	db := openTestDB(t)
+	var active int64
+	if err := db.Model(&models.Warehouse{}).Where("active = ?", true).Count(&active).Error; err != nil {
+		t.Fatal(err)
+	}
+	if active > 0 {
+		t.Skip("test needs a database without active warehouses")
+	}
+	products := newTestProductService(db)
+
+	// baris pertama gagal setelah produk di-insert karena tidak ada gudang aktif untuk
+	// stoknya; baris kedua tanpa harga tidak boleh melihat produk tersebut dan "mengubahnya"
+	sku := "PARTIAL-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	csv := "sku,name,price,stock\n" +
+		sku + ",Produk baru,10.00,5\n" +
+		sku + ",Nama baru,,\n"
+	report, err := products.Import(strings.NewReader(csv), ImportFormatCSV, false, 1)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if report.Updated != 0 || report.Created != 0 || len(report.Errors) != 2 {
+		t.Fatalf("report = %+v, want both rows to fail", report)
+	}
+	if report.Errors[0].Error != ErrNoActiveWarehouse.Error() {
+		t.Errorf("row 2 error = %q, want %q", report.Errors[0].Error, ErrNoActiveWarehouse)
+	}
+}
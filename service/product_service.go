@@ -1,34 +1,526 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wahyuutomoputra/order-management/dto"
 	"github.com/wahyuutomoputra/order-management/models"
 	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidProductFilter = errors.New("invalid product filter")
+	ErrVariantNotFound      = repository.ErrVariantNotFound
+	ErrVariantSKUTaken      = errors.New("variant SKU already exists")
+	ErrProductSKUTaken      = errors.New("product SKU already exists")
+	ErrProductReferenced    = errors.New("product is referenced by existing orders")
+	ErrVersionConflict      = errors.New("product has been modified by another request")
+	// ErrStockManagedByVariants dikembalikan saat stok produk bervarian diubah langsung
+	ErrStockManagedByVariants = errors.New("stock of a product with variants is managed per variant")
+	// ErrStockInMultipleWarehouses dikembalikan saat stok absolut dikirim untuk produk yang
+	// stoknya tersebar di beberapa gudang, karena selisihnya tidak jelas milik gudang mana
+	ErrStockInMultipleWarehouses = errors.New("product is stocked in several warehouses; adjust stock per warehouse instead")
 )
 
+// AnyVersion sebagai versi yang diharapkan berarti update tanpa pengecekan versi,
+// dipakai untuk If-Match: *. Versi produk selalu dimulai dari 1.
+const AnyVersion uint = 0
+
+// productSortColumns memetakan parameter sort ke kolom tabel products
+var productSortColumns = map[string]string{
+	"id":    "id",
+	"name":  "name",
+	"price": "price",
+	"stock": "stock",
+}
+
 type ProductService struct {
-	repo *repository.ProductRepository
+	repo         *repository.ProductRepository
+	inventory    *repository.InventoryRepository
+	categoryRepo *repository.CategoryRepository
+	tagRepo      *repository.TagRepository
+	// storage dipakai untuk menghapus file gambar saat produk di-purge
+	storage Storage
+}
+
+func NewProductService(repo *repository.ProductRepository, inventory *repository.InventoryRepository, categoryRepo *repository.CategoryRepository, tagRepo *repository.TagRepository) *ProductService {
+	return &ProductService{repo: repo, inventory: inventory, categoryRepo: categoryRepo, tagRepo: tagRepo}
+}
+
+// SetImageStorage mengatur storage gambar produk
+func (s *ProductService) SetImageStorage(storage Storage) {
+	s.storage = storage
 }
 
-func NewProductService(repo *repository.ProductRepository) *ProductService {
-	return &ProductService{repo}
+// Create menyimpan produk baru beserta kategori dan tag-nya.
+// Stok awal dicatat sebagai movement restock oleh actorID.
+func (s *ProductService) Create(product *models.Product, categoryIDs []uint, tagNames []string, actorID uint) error {
+	return runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		return s.createTx(tx, product, categoryIDs, tagNames, actorID)
+	})
 }
 
-func (s *ProductService) Create(product *models.Product) error {
-	return s.repo.Create(product)
+// createTx adalah isi Create yang berjalan di transaksi milik pemanggil
+func (s *ProductService) createTx(tx *gorm.DB, product *models.Product, categoryIDs []uint, tagNames []string, actorID uint) error {
+	repoTx := s.repo.WithTx(tx)
+	if err := s.checkProductSKU(repoTx, product); err != nil {
+		return err
+	}
+	if err := s.resolveTaxonomy(tx, product, categoryIDs, tagNames); err != nil {
+		return err
+	}
+	stock := product.Stock
+	product.Stock = 0
+	product.Version = 1
+	if err := repoTx.Create(product); err != nil {
+		return err
+	}
+	product.Stock = stock
+	_, err := s.inventory.WithTx(tx).Apply(&models.InventoryMovement{
+		ProductID: product.ID,
+		Delta:     stock,
+		Reason:    models.MovementRestock,
+		ActorID:   actorID,
+		Note:      "initial stock",
+	})
+	return err
 }
 
-func (s *ProductService) FindAll() ([]models.Product, error) {
-	return s.repo.FindAll()
+// List mengembalikan daftar produk sesuai filter, sort dan pagination
+func (s *ProductService) List(filter dto.ProductFilter) ([]models.Product, dto.PageMeta, error) {
+	filter.Normalize()
+	q := repository.ProductQuery{
+		Name:    strings.TrimSpace(filter.Q),
+		InStock: filter.InStock,
+		SortBy:  "id",
+		Offset:  filter.Offset(),
+		Limit:   filter.Limit,
+	}
+	if filter.Sort != "" {
+		q.SortDesc = strings.HasPrefix(filter.Sort, "-")
+		q.SortBy = productSortColumns[strings.TrimPrefix(filter.Sort, "-")]
+	}
+	if filter.CategoryID != 0 {
+		categories, err := s.categoryRepo.FindAll()
+		if err != nil {
+			return nil, dto.PageMeta{}, err
+		}
+		q.CategoryIDs = descendantCategoryIDs(categories, filter.CategoryID)
+	}
+	if filter.Tag != "" {
+		q.Tag = normalizeTagName(filter.Tag)
+	}
+	if filter.MinPrice != "" {
+		m, err := models.ParseMoney(filter.MinPrice)
+		if err != nil {
+			return nil, dto.PageMeta{}, fmt.Errorf("%w: min_price", ErrInvalidProductFilter)
+		}
+		q.MinPrice = &m.Amount
+	}
+	if filter.MaxPrice != "" {
+		m, err := models.ParseMoney(filter.MaxPrice)
+		if err != nil {
+			return nil, dto.PageMeta{}, fmt.Errorf("%w: max_price", ErrInvalidProductFilter)
+		}
+		q.MaxPrice = &m.Amount
+	}
+
+	products, total, err := s.repo.FindPage(q)
+	if err != nil {
+		return nil, dto.PageMeta{}, err
+	}
+	return products, dto.NewPageMeta(filter.PageQuery, total), nil
 }
 
 func (s *ProductService) FindByID(id uint) (*models.Product, error) {
 	return s.repo.FindByID(id)
 }
 
-func (s *ProductService) Update(product *models.Product) error {
-	return s.repo.Update(product)
+// Update menyimpan perubahan produk. categoryIDs/tagNames nil berarti relasi tidak diubah.
+// Stok hanya diubah jika stock tidak nil; stock adalah total stok di semua gudang dan
+// selisihnya terhadap stok terkini dicatat sebagai movement adjustment oleh actorID di
+// gudang yang memegang stok produk (gudang default jika belum ada stok). Produk yang
+// stoknya ada di beberapa gudang ditolak dengan ErrStockInMultipleWarehouses; gunakan
+// InventoryService.Adjust per gudang.
+// version adalah versi produk yang dilihat client; jika produk sudah berubah sejak itu
+// dikembalikan ErrVersionConflict. AnyVersion melewati pengecekan tersebut.
+func (s *ProductService) Update(product *models.Product, version uint, categoryIDs []uint, tagNames []string, stock *int, actorID uint) error {
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		return s.updateTx(tx, product, version, categoryIDs, tagNames, stock, actorID)
+	})
+	if err != nil {
+		return err
+	}
+	// response menampilkan stok yang tersedia seperti saat produk dibaca
+	return s.repo.FillActiveStock(product)
+}
+
+// updateTx adalah isi Update yang berjalan di transaksi milik pemanggil
+func (s *ProductService) updateTx(tx *gorm.DB, product *models.Product, version uint, categoryIDs []uint, tagNames []string, stock *int, actorID uint) error {
+	repoTx := s.repo.WithTx(tx)
+	inventoryTx := s.inventory.WithTx(tx)
+	// stok gudang dikunci sebelum baris produk, sama dengan urutan lock checkout
+	levels, err := inventoryTx.LockLevels(product.ID, 0)
+	if err != nil {
+		return err
+	}
+	current, err := repoTx.FindForUpdate(product.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		return err
+	}
+	if version == AnyVersion {
+		version = current.Version
+	}
+	if current.Version != version {
+		return ErrVersionConflict
+	}
+	// stok diambil dari baris yang dikunci, bukan dari product yang mungkin sudah basi
+	product.Stock = current.Stock
+	product.Version = version
+	delta := 0
+	if stock != nil {
+		delta = *stock - current.Stock
+	}
+	if delta != 0 && len(current.Variants) > 0 {
+		return ErrStockManagedByVariants
+	}
+	var warehouseID uint
+	if delta != 0 {
+		if warehouseID, err = absoluteStockWarehouse(levels); err != nil {
+			return err
+		}
+	}
+	if err := s.checkProductSKU(repoTx, product); err != nil {
+		return err
+	}
+	ok, err := repoTx.Update(product)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrVersionConflict
+	}
+	ok, err = inventoryTx.Apply(&models.InventoryMovement{
+		ProductID:   product.ID,
+		WarehouseID: warehouseID,
+		Delta:       delta,
+		Reason:      models.MovementAdjustment,
+		ActorID:     actorID,
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInsufficientStock
+	}
+	product.Stock += delta
+	if err := s.resolveTaxonomy(tx, product, categoryIDs, tagNames); err != nil {
+		return err
+	}
+	if categoryIDs != nil {
+		if err := repoTx.ReplaceCategories(product, product.Categories); err != nil {
+			return err
+		}
+	}
+	if tagNames != nil {
+		if err := repoTx.ReplaceTags(product, product.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// absoluteStockWarehouse memilih gudang untuk selisih stok absolut: satu-satunya gudang
+// yang memegang stok produk, atau 0 (gudang default) jika produk belum punya stok
+func absoluteStockWarehouse(levels []models.StockLevel) (uint, error) {
+	var warehouseID uint
+	for _, level := range levels {
+		if level.Quantity == 0 {
+			continue
+		}
+		if warehouseID != 0 {
+			return 0, ErrStockInMultipleWarehouses
+		}
+		warehouseID = level.WarehouseID
+	}
+	return warehouseID, nil
+}
+
+// resolveTaxonomy mengisi product.Categories dan product.Tags dari input request.
+// Tag yang belum ada dibuat otomatis, kategori yang tidak ada ditolak.
+func (s *ProductService) resolveTaxonomy(tx *gorm.DB, product *models.Product, categoryIDs []uint, tagNames []string) error {
+	if categoryIDs != nil {
+		categories, err := s.categoryRepo.WithTx(tx).FindByIDs(categoryIDs)
+		if err != nil {
+			return err
+		}
+		if len(categories) != len(uniqueIDs(categoryIDs)) {
+			return ErrCategoryNotFound
+		}
+		product.Categories = categories
+	}
+	if tagNames != nil {
+		tags, err := s.tagRepo.WithTx(tx).FindOrCreateByNames(normalizeTagNames(tagNames))
+		if err != nil {
+			return err
+		}
+		product.Tags = tags
+	}
+	return nil
+}
+
+func uniqueIDs(ids []uint) map[uint]bool {
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
 }
 
+// Delete mengarsipkan produk sehingga tidak tampil di katalog dan tidak bisa diorder,
+// namun tetap bisa dirujuk dari riwayat order
 func (s *ProductService) Delete(product *models.Product) error {
 	return s.repo.Delete(product)
 }
+
+func (s *ProductService) ListArchived(page dto.PageQuery) ([]models.Product, dto.PageMeta, error) {
+	page.Normalize()
+	products, total, err := s.repo.FindArchived(page.Offset(), page.Limit)
+	if err != nil {
+		return nil, dto.PageMeta{}, err
+	}
+	return products, dto.NewPageMeta(page, total), nil
+}
+
+// Restore mengembalikan produk arsip ke katalog
+func (s *ProductService) Restore(id uint) (*models.Product, error) {
+	product, err := s.repo.FindArchivedByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	if err := s.repo.Restore(product); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(id)
+}
+
+// Purge menghapus produk secara permanen. Produk yang masih dirujuk order ditolak
+// agar riwayat order tetap utuh.
+func (s *ProductService) Purge(id uint) error {
+	var imageKeys []string
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		// stok gudang dikunci lebih dulu karena penghapusan varian dan produk mengikuti
+		if err := s.inventory.WithTx(tx).LockProductLevels(id); err != nil {
+			return err
+		}
+		product, err := repoTx.FindByIDUnscoped(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		refs, err := repoTx.CountOrderReferences(id)
+		if err != nil {
+			return err
+		}
+		if refs > 0 {
+			return ErrProductReferenced
+		}
+		if imageKeys, err = repoTx.FindImageKeys(id); err != nil {
+			return err
+		}
+		return repoTx.Purge(product)
+	})
+	if err != nil {
+		return err
+	}
+	if s.storage != nil {
+		deleteStoredFiles(context.Background(), s.storage, imageKeys)
+	}
+	return nil
+}
+
+// CreateVariant menambah varian ke produk. Stok varian dicatat sebagai movement restock
+// sehingga ikut menambah stok agregat produk.
+func (s *ProductService) CreateVariant(productID uint, req dto.VariantRequest, actorID uint) (*models.ProductVariant, error) {
+	variant := models.ProductVariant{
+		ProductID: productID,
+		SKU:       strings.TrimSpace(req.SKU),
+		Options:   req.Options,
+		Price:     req.Price,
+	}
+	stock := 0
+	if req.Stock != nil {
+		stock = *req.Stock
+	}
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		if _, err := repoTx.FindByID(productID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		if err := s.checkVariantSKU(repoTx, variant.SKU, 0); err != nil {
+			return err
+		}
+		if err := repoTx.CreateVariant(&variant); err != nil {
+			return err
+		}
+		ok, err := s.inventory.WithTx(tx).Apply(&models.InventoryMovement{
+			ProductID: productID,
+			VariantID: &variant.ID,
+			Delta:     stock,
+			Reason:    models.MovementRestock,
+			ActorID:   actorID,
+			Note:      "initial stock",
+		})
+		if err != nil {
+			return err
+		}
+		// produk terhapus setelah dibaca; stok gudang yang terlanjur ditulis ikut di-rollback
+		if !ok {
+			return ErrProductNotFound
+		}
+		variant.Stock = stock
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// UpdateVariant mengubah varian. Stok hanya diubah jika dikirim; selisihnya dicatat
+// sebagai movement adjustment
+func (s *ProductService) UpdateVariant(productID, variantID uint, req dto.VariantRequest, actorID uint) (*models.ProductVariant, error) {
+	var result *models.ProductVariant
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		inventoryTx := s.inventory.WithTx(tx)
+		// stok gudang dikunci sebelum baris varian, sama dengan urutan lock checkout
+		if _, err := inventoryTx.LockLevels(productID, variantID); err != nil {
+			return err
+		}
+		variant, err := repoTx.FindVariantForUpdate(productID, variantID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVariantNotFound
+			}
+			return err
+		}
+		sku := strings.TrimSpace(req.SKU)
+		if err := s.checkVariantSKU(repoTx, sku, variant.ID); err != nil {
+			return err
+		}
+		delta := 0
+		if req.Stock != nil {
+			delta = *req.Stock - variant.Stock
+		}
+		variant.SKU = sku
+		variant.Options = req.Options
+		variant.Price = req.Price
+		if err := repoTx.UpdateVariant(variant); err != nil {
+			return err
+		}
+		ok, err := inventoryTx.Apply(&models.InventoryMovement{
+			ProductID: productID,
+			VariantID: &variant.ID,
+			Delta:     delta,
+			Reason:    models.MovementAdjustment,
+			ActorID:   actorID,
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInsufficientStock
+		}
+		variant.Stock += delta
+		result = variant
+		return nil
+	})
+	return result, err
+}
+
+// DeleteVariant menghapus varian. Sisa stok varian di tiap gudang dicatat sebagai movement
+// adjustment negatif sehingga stok agregat produk ikut berkurang.
+func (s *ProductService) DeleteVariant(productID, variantID uint, actorID uint) error {
+	return runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		inventoryTx := s.inventory.WithTx(tx)
+		// stok gudang dikunci sebelum baris varian, sama dengan urutan lock checkout
+		levels, err := inventoryTx.LockLevels(productID, variantID)
+		if err != nil {
+			return err
+		}
+		variant, err := repoTx.FindVariantForUpdate(productID, variantID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVariantNotFound
+			}
+			return err
+		}
+		for _, level := range levels {
+			ok, err := inventoryTx.Apply(&models.InventoryMovement{
+				ProductID:   productID,
+				VariantID:   &variant.ID,
+				WarehouseID: level.WarehouseID,
+				Delta:       -level.Quantity,
+				Reason:      models.MovementAdjustment,
+				ActorID:     actorID,
+				Note:        "variant deleted",
+			})
+			if err != nil {
+				return err
+			}
+			// stok gudang sudah dikunci, sehingga false berarti stok tidak sama dengan yang
+			// dibaca; batalkan agar stok varian tidak dihapus tanpa movement
+			if !ok {
+				return ErrInsufficientStock
+			}
+		}
+		if err := inventoryTx.DeleteLevels(productID, variant.ID); err != nil {
+			return err
+		}
+		return repoTx.DeleteVariant(variant)
+	})
+}
+
+// checkProductSKU memastikan SKU produk belum dipakai produk lain, termasuk produk arsip
+func (s *ProductService) checkProductSKU(repo *repository.ProductRepository, product *models.Product) error {
+	if product.SKU == nil {
+		return nil
+	}
+	existing, err := repo.FindBySKU(*product.SKU)
+	if err == nil && existing.ID != product.ID {
+		return ErrProductSKUTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *ProductService) checkVariantSKU(repo *repository.ProductRepository, sku string, exceptID uint) error {
+	existing, err := repo.FindVariantBySKU(sku)
+	if err == nil && existing.ID != exceptID {
+		return ErrVariantSKUTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
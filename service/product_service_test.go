@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+func newTestProductService(db *gorm.DB) *ProductService {
+	return NewProductService(repository.NewProductRepository(db), repository.NewInventoryRepository(db),
+		repository.NewCategoryRepository(db), repository.NewTagRepository(db))
+}
+
+func TestProductStockCountsActiveWarehousesOnly(t *testing.T) {
+	db := openTestDB(t)
+	active := seedWarehouse(t, db, 0)
+	inactive := seedWarehouse(t, db, 1)
+	product := seedProduct(t, db, active, 3)
+	restock(t, db, product.ID, inactive.ID, 5)
+	if err := db.Model(&inactive).Update("active", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	products := newTestProductService(db)
+
+	got, err := products.FindByID(product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Stock != 3 {
+		t.Errorf("FindByID stock = %d, want 3 (inactive warehouse excluded)", got.Stock)
+	}
+
+	// stok absolut tidak bisa dibagi ke beberapa gudang
+	stock := 10
+	if err := products.Update(got, got.Version, nil, nil, &stock, 1); !errors.Is(err, ErrStockInMultipleWarehouses) {
+		t.Errorf("Update stock with two stocked warehouses: error = %v, want ErrStockInMultipleWarehouses", err)
+	}
+}
+
+func TestUpdateAbsoluteStockUsesStockedWarehouse(t *testing.T) {
+	db := openTestDB(t)
+	seedWarehouse(t, db, 0)
+	secondary := seedWarehouse(t, db, 1)
+	// seluruh stok ada di gudang yang bukan gudang default
+	product := seedProduct(t, db, secondary, 8)
+	products := newTestProductService(db)
+
+	current, err := products.FindByID(product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stock := 5
+	if err := products.Update(current, current.Version, nil, nil, &stock, 1); err != nil {
+		t.Fatalf("Update stock: %v", err)
+	}
+	if current.Stock != 5 {
+		t.Errorf("stock after update = %d, want 5", current.Stock)
+	}
+	var level models.StockLevel
+	if err := db.Where("product_id = ? AND warehouse_id = ?", product.ID, secondary.ID).First(&level).Error; err != nil {
+		t.Fatal(err)
+	}
+	if level.Quantity != 5 {
+		t.Errorf("stocked warehouse quantity = %d, want 5", level.Quantity)
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var ErrNoReservation = errors.New("no active reservation")
+
+// ReservationService menahan stok selama checkout. Reservasi tidak mengubah stok,
+// tetapi dikurangkan dari stok yang bisa dialokasikan untuk customer lain sampai
+// dikonversi menjadi order, dilepas, atau kedaluwarsa.
+type ReservationService struct {
+	repo        *repository.ReservationRepository
+	inventory   *repository.InventoryRepository
+	productRepo *repository.ProductRepository
+	allocator   AllocationStrategy
+	ttl         time.Duration
+}
+
+func NewReservationService(repo *repository.ReservationRepository, inventory *repository.InventoryRepository, productRepo *repository.ProductRepository, allocator AllocationStrategy, ttl time.Duration) *ReservationService {
+	return &ReservationService{repo: repo, inventory: inventory, productRepo: productRepo, allocator: allocator, ttl: ttl}
+}
+
+// Reserve menahan stok untuk items selama TTL. Reservasi aktif sebelumnya milik user
+// diganti, sehingga memanggil Reserve ulang memperpanjang dan menyesuaikan reservasi.
+func (s *ReservationService) Reserve(userID uint, items []dto.OrderItemInput) (*dto.ReservationResponse, error) {
+	items = mergeOrderItems(items)
+	now := time.Now()
+	expiresAt := now.Add(s.ttl).Unix()
+	var reservations []models.StockReservation
+	err := runInTx(s.inventory.DB(), func(tx *gorm.DB) error {
+		reservations = nil
+		repoTx := s.repo.WithTx(tx)
+		if err := repoTx.ReleaseByUser(userID); err != nil {
+			return err
+		}
+		// items terurut berdasarkan product ID lalu variant ID, sama seperti pembuatan
+		// order, sehingga row lock stok gudang selalu diambil dengan urutan yang sama
+		for _, item := range items {
+			product, err := s.productRepo.WithTx(tx).FindByID(item.ProductID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrProductNotFound
+				}
+				return err
+			}
+			if item.VariantID == 0 && len(product.Variants) > 0 {
+				return ErrVariantRequired
+			}
+			if item.VariantID != 0 && !hasVariant(product, item.VariantID) {
+				return ErrVariantNotFound
+			}
+			levels, err := lockAvailableLevels(s.inventory.WithTx(tx), repoTx, item.ProductID, item.VariantID, userID, now.Unix())
+			if err != nil {
+				return err
+			}
+			allocations, ok := s.allocator.Allocate(levels, item.Quantity)
+			if !ok {
+				return fmt.Errorf("%w for product: %s", ErrInsufficientStock, product.Name)
+			}
+			for _, allocation := range allocations {
+				reservations = append(reservations, models.StockReservation{
+					UserID:      userID,
+					ProductID:   item.ProductID,
+					VariantID:   item.VariantID,
+					WarehouseID: allocation.WarehouseID,
+					Quantity:    allocation.Quantity,
+					Status:      models.ReservationActive,
+					ExpiresAt:   expiresAt,
+				})
+			}
+		}
+		return repoTx.Create(reservations)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReservationResponse{Reservations: reservations, ExpiresAt: expiresAt}, nil
+}
+
+// Active mengembalikan reservasi aktif milik user
+func (s *ReservationService) Active(userID uint) (*dto.ReservationResponse, error) {
+	reservations, err := s.repo.FindActiveByUser(userID, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	if len(reservations) == 0 {
+		return nil, ErrNoReservation
+	}
+	return &dto.ReservationResponse{Reservations: reservations, ExpiresAt: reservations[0].ExpiresAt}, nil
+}
+
+// Available mengembalikan jumlah stok produk/varian yang masih bisa dibeli userID: stok
+// di gudang aktif dikurangi reservasi aktif milik user lain, dihitung sama seperti saat
+// Reserve dan checkout tetapi tanpa row lock. variantID 0 untuk produk tanpa varian.
+func (s *ReservationService) Available(productID, variantID, userID uint) (int, error) {
+	levels, err := s.inventory.FindActiveLevels(productID, variantID)
+	if err != nil {
+		return 0, err
+	}
+	levels, err = subtractHeldByOthers(s.repo, levels, productID, variantID, userID, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, level := range levels {
+		total += level.Quantity
+	}
+	return total, nil
+}
+
+// Release melepas semua reservasi aktif milik user
+func (s *ReservationService) Release(userID uint) error {
+	return s.repo.ReleaseByUser(userID)
+}
+
+// SweepExpired menandai reservasi yang TTL-nya habis sebagai expired
+func (s *ReservationService) SweepExpired() (int64, error) {
+	return s.repo.ExpireBefore(time.Now().Unix())
+}
+
+// RunSweeper menjalankan SweepExpired secara berkala sampai ctx selesai.
+// Dipanggil sebagai goroutine saat server start; interval <= 0 mematikan sweeper.
+func (s *ReservationService) RunSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.SweepExpired()
+			if err != nil {
+				log.Printf("reservation sweeper: %v", err)
+				continue
+			}
+			if released > 0 {
+				log.Printf("reservation sweeper: released %d expired reservations", released)
+			}
+		}
+	}
+}
+
+// lockAvailableLevels mengunci stok gudang produk/varian lalu mengurangi jumlahnya dengan
+// reservasi aktif milik user lain, sehingga hanya stok yang benar-benar bebas yang bisa
+// dialokasikan. Reservasi milik userID sendiri tetap dihitung sebagai stok tersedia.
+// Reservasi dibaca dengan locking read karena transaksi pemanggil sudah membaca tabel lain
+// (misalnya produk) sebelum row lock stok didapat, sehingga snapshot REPEATABLE READ-nya
+// bisa tidak memuat reservasi yang di-commit transaksi lain selama menunggu lock.
+func lockAvailableLevels(inventoryTx *repository.InventoryRepository, reservationsTx *repository.ReservationRepository, productID, variantID, userID uint, now int64) ([]models.StockLevel, error) {
+	levels, err := inventoryTx.FindLevelsForUpdate(productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	held, err := reservationsTx.SumHeldByOthersLocked(productID, variantID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	return subtractHeld(levels, held), nil
+}
+
+// subtractHeldByOthers mengurangi stok tiap gudang dengan reservasi aktif milik user lain
+func subtractHeldByOthers(reservations *repository.ReservationRepository, levels []models.StockLevel, productID, variantID, userID uint, now int64) ([]models.StockLevel, error) {
+	held, err := reservations.SumHeldByOthers(productID, variantID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	return subtractHeld(levels, held), nil
+}
+
+func subtractHeld(levels []models.StockLevel, held map[uint]int) []models.StockLevel {
+	for i := range levels {
+		levels[i].Quantity = max(levels[i].Quantity-held[levels[i].WarehouseID], 0)
+	}
+	return levels
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/config"
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+// TestReserveAndOrderRaceForLastUnit memastikan reservasi yang di-commit user lain selama
+// menunggu row lock stok ikut terhitung, sehingga unit terakhir hanya didapat satu user.
+// Snapshot REPEATABLE READ hanya ada di MySQL.
+func TestReserveAndOrderRaceForLastUnit(t *testing.T) {
+	requireMySQL(t)
+	const users = 20
+	db := openTestDB(t)
+	warehouse := seedWarehouse(t, db, 0)
+	product := seedProduct(t, db, warehouse, 1)
+	reservations := NewReservationService(
+		repository.NewReservationRepository(db),
+		repository.NewInventoryRepository(db),
+		repository.NewProductRepository(db),
+		PriorityFill{},
+		time.Hour,
+	)
+	orders := NewOrderService(
+		repository.NewOrderRepository(db),
+		repository.NewInventoryRepository(db),
+		repository.NewReservationRepository(db),
+		config.OrderConfig{},
+	)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded []uint
+	)
+	items := []dto.OrderItemInput{{ProductID: product.ID, Quantity: 1}}
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(userID uint, reserve bool) {
+			defer wg.Done()
+			var err error
+			if reserve {
+				_, err = reservations.Reserve(userID, items)
+			} else {
+				_, err = orders.CreateOrder(userID, items)
+			}
+			if err != nil && !errors.Is(err, ErrInsufficientStock) {
+				t.Errorf("user %d: unexpected error: %v", userID, err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded = append(succeeded, userID)
+				mu.Unlock()
+			}
+		}(uint(2_000_000+int(product.ID)*users+i), i%2 == 0)
+	}
+	wg.Wait()
+
+	if len(succeeded) != 1 {
+		t.Errorf("users that got the last unit = %v, want exactly one", succeeded)
+	}
+}
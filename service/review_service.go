@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReviewNotFound   = errors.New("review not found")
+	ErrReviewExists     = errors.New("you have already reviewed this product")
+	ErrNotVerifiedBuyer = errors.New("only customers who received this product can review it")
+)
+
+type ReviewService struct {
+	repo        *repository.ReviewRepository
+	orderRepo   *repository.OrderRepository
+	productRepo *repository.ProductRepository
+}
+
+func NewReviewService(repo *repository.ReviewRepository, orderRepo *repository.OrderRepository, productRepo *repository.ProductRepository) *ReviewService {
+	return &ReviewService{repo: repo, orderRepo: orderRepo, productRepo: productRepo}
+}
+
+// Create menyimpan review dari user yang pernah menerima produk tersebut (order delivered).
+// Review langsung dipublikasikan dan ikut dihitung di rating produk.
+func (s *ReviewService) Create(productID, userID uint, req dto.ReviewRequest) (*models.Review, error) {
+	review := &models.Review{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Body:      strings.TrimSpace(req.Body),
+		Status:    models.ReviewPublished,
+	}
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		// lock baris produk agar review yang masuk bersamaan tidak saling menimpa rating
+		if _, err := s.productRepo.WithTx(tx).FindForUpdate(productID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		orderID, err := s.orderRepo.WithTx(tx).FindDeliveredOrderWithProduct(userID, productID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotVerifiedBuyer
+			}
+			return err
+		}
+		repoTx := s.repo.WithTx(tx)
+		exists, err := repoTx.ExistsForUser(productID, userID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrReviewExists
+		}
+		review.OrderID = orderID
+		if err := repoTx.Create(review); err != nil {
+			return err
+		}
+		return repoTx.RefreshProductRating(productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// ListPublished mengembalikan review produk yang dipublikasikan, terbaru dulu
+func (s *ReviewService) ListPublished(productID uint, page dto.PageQuery) ([]models.Review, dto.PageMeta, error) {
+	if _, err := s.productRepo.FindByID(productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.PageMeta{}, ErrProductNotFound
+		}
+		return nil, dto.PageMeta{}, err
+	}
+	page.Normalize()
+	reviews, total, err := s.repo.FindPage(repository.ReviewQuery{
+		ProductID: productID,
+		Status:    models.ReviewPublished,
+		Offset:    page.Offset(),
+		Limit:     page.Limit,
+	})
+	if err != nil {
+		return nil, dto.PageMeta{}, err
+	}
+	return reviews, dto.NewPageMeta(page, total), nil
+}
+
+// List mengembalikan semua review sesuai filter untuk moderasi admin
+func (s *ReviewService) List(filter dto.ReviewFilter) ([]models.Review, dto.PageMeta, error) {
+	filter.Normalize()
+	reviews, total, err := s.repo.FindPage(repository.ReviewQuery{
+		ProductID: filter.ProductID,
+		Status:    models.ReviewStatus(filter.Status),
+		Offset:    filter.Offset(),
+		Limit:     filter.Limit,
+	})
+	if err != nil {
+		return nil, dto.PageMeta{}, err
+	}
+	return reviews, dto.NewPageMeta(filter.PageQuery, total), nil
+}
+
+// UpdateStatus mempublikasikan atau menyembunyikan review lalu menghitung ulang rating produk
+func (s *ReviewService) UpdateStatus(id uint, status models.ReviewStatus) (*models.Review, error) {
+	var review *models.Review
+	err := runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		var err error
+		if review, err = s.findReview(repoTx, id); err != nil {
+			return err
+		}
+		if review.Status == status {
+			return nil
+		}
+		if err := repoTx.UpdateStatus(review, status); err != nil {
+			return err
+		}
+		review.Status = status
+		return repoTx.RefreshProductRating(review.ProductID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Delete menghapus review secara permanen lalu menghitung ulang rating produk
+func (s *ReviewService) Delete(id uint) error {
+	return runInTx(s.repo.DB(), func(tx *gorm.DB) error {
+		repoTx := s.repo.WithTx(tx)
+		review, err := s.findReview(repoTx, id)
+		if err != nil {
+			return err
+		}
+		if err := repoTx.Delete(review); err != nil {
+			return err
+		}
+		return repoTx.RefreshProductRating(review.ProductID)
+	})
+}
+
+func (s *ReviewService) findReview(repo *repository.ReviewRepository, id uint) (*models.Review, error) {
+	review, err := repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return review, nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+)
+
+const (
+	// maxSearchCandidates membatasi kandidat dari index yang diranking ulang per query
+	maxSearchCandidates = 1000
+	// maxSearchTerms membatasi jumlah kata yang dipakai dari query
+	maxSearchTerms = 8
+)
+
+var ErrInvalidSearchQuery = errors.New("search query must contain at least one letter or digit")
+
+// SearchIndex mengembalikan kandidat produk yang mungkin cocok dengan term pencarian.
+// Ranking dan highlight dilakukan SearchService sehingga hasilnya sama untuk setiap
+// implementasi index.
+type SearchIndex interface {
+	Candidates(ctx context.Context, terms []string, limit int) ([]models.Product, error)
+}
+
+type SearchService struct {
+	index SearchIndex
+}
+
+func NewSearchService(index SearchIndex) *SearchService {
+	return &SearchService{index: index}
+}
+
+// Search mencari produk dengan typo-tolerant prefix matching, diurutkan berdasarkan
+// relevansi lalu ketersediaan stok
+func (s *SearchService) Search(ctx context.Context, query dto.ProductSearchQuery) ([]dto.ProductSearchHit, dto.PageMeta, error) {
+	query.Normalize()
+	terms := searchTerms(query.Q)
+	if len(terms) == 0 {
+		return nil, dto.PageMeta{}, ErrInvalidSearchQuery
+	}
+	candidates, err := s.index.Candidates(ctx, terms, maxSearchCandidates)
+	if err != nil {
+		return nil, dto.PageMeta{}, err
+	}
+
+	hits := make([]dto.ProductSearchHit, 0, len(candidates))
+	for _, product := range candidates {
+		if score := scoreProduct(product, terms); score > 0 {
+			hits = append(hits, dto.ProductSearchHit{Product: product, Score: score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if inStockA, inStockB := a.Product.Stock > 0, b.Product.Stock > 0; inStockA != inStockB {
+			return inStockA
+		}
+		if a.Product.Stock != b.Product.Stock {
+			return a.Product.Stock > b.Product.Stock
+		}
+		return a.Product.ID < b.Product.ID
+	})
+
+	meta := dto.NewPageMeta(query.PageQuery, int64(len(hits)))
+	start := min(query.Offset(), len(hits))
+	page := hits[start:min(start+query.Limit, len(hits))]
+	for i := range page {
+		page[i].Highlight = highlightTerms(page[i].Product.Name, terms)
+	}
+	return page, meta, nil
+}
+
+// searchTerms memecah query menjadi kata huruf kecil yang unik; karakter selain huruf
+// dan angka menjadi pemisah sehingga operator full-text tidak ikut terkirim
+func searchTerms(q string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, term := range searchTokens(q) {
+		if !seen[term] && len(terms) < maxSearchTerms {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func searchTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// scoreProduct menghitung relevansi produk: kata di nama bernilai penuh, SKU sedikit
+// lebih tinggi jika sama persis, tag setengahnya. Produk yang cocok dengan semua term
+// mendapat bonus agar berada di atas produk yang hanya cocok sebagian.
+func scoreProduct(product models.Product, terms []string) float64 {
+	nameTokens := searchTokens(product.Name)
+	var skuTokens, tagTokens []string
+	if product.SKU != nil {
+		skuTokens = searchTokens(*product.SKU)
+	}
+	for _, tag := range product.Tags {
+		tagTokens = append(tagTokens, searchTokens(tag.Name)...)
+	}
+
+	var score float64
+	matched := 0
+	for _, term := range terms {
+		best := max(bestMatch(term, nameTokens), 1.2*bestMatch(term, skuTokens), 0.5*bestMatch(term, tagTokens))
+		if best > 0 {
+			matched++
+		}
+		score += best
+	}
+	if matched == len(terms) && len(terms) > 1 {
+		score += 1
+	}
+	return score
+}
+
+func bestMatch(term string, tokens []string) float64 {
+	var best float64
+	for _, token := range tokens {
+		best = max(best, matchTerm(term, token))
+	}
+	return best
+}
+
+// matchTerm menilai kecocokan satu term dengan satu kata: sama persis 3, awalan 2,
+// salah ketik (jarak edit kecil terhadap kata atau awalannya) 1. Salah ketik hanya
+// ditoleransi jika huruf pertamanya sama agar kata lain tidak ikut cocok.
+func matchTerm(term, token string) float64 {
+	switch {
+	case term == token:
+		return 3
+	case strings.HasPrefix(token, term):
+		return 2
+	}
+	allowed := typoAllowance(term)
+	if allowed == 0 || []rune(term)[0] != []rune(token)[0] {
+		return 0
+	}
+	if editDistance(term, token) <= allowed {
+		return 1
+	}
+	if prefix := runePrefix(token, len([]rune(term))); prefix != token && editDistance(term, prefix) <= allowed {
+		return 1
+	}
+	return 0
+}
+
+// typoAllowance adalah jumlah salah ketik yang ditoleransi; kata pendek harus tepat
+func typoAllowance(term string) int {
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+func runePrefix(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// editDistance menghitung jarak Damerau-Levenshtein sederhana (termasuk pertukaran dua huruf berdekatan)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
+
+// highlightTerms mengembalikan teks yang sudah di-escape dengan kata yang cocok dibungkus <mark>
+func highlightTerms(text string, terms []string) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+			j++
+		}
+		word := string(runes[i:j])
+		if bestMatchTerms(strings.ToLower(word), terms) > 0 {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func bestMatchTerms(token string, terms []string) float64 {
+	var best float64
+	for _, term := range terms {
+		best = max(best, matchTerm(term, token))
+	}
+	return best
+}
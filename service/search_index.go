@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+)
+
+// NewSearchIndex memilih index pencarian berdasarkan nama backend; nilai yang tidak
+// dikenal memakai MySQLSearchIndex
+func NewSearchIndex(backend string, productRepo *repository.ProductRepository) SearchIndex {
+	switch backend {
+	case "memory":
+		return NewMemorySearchIndex(nil)
+	default:
+		return &MySQLSearchIndex{repo: productRepo}
+	}
+}
+
+// MySQLSearchIndex mencari kandidat dengan index FULLTEXT pada nama, SKU, dan tag produk
+type MySQLSearchIndex struct {
+	repo *repository.ProductRepository
+}
+
+func (i *MySQLSearchIndex) Candidates(ctx context.Context, terms []string, limit int) ([]models.Product, error) {
+	return i.repo.WithTx(i.repo.DB().WithContext(ctx)).SearchFullText(fullTextExpr(terms), limit)
+}
+
+// fullTextExpr membentuk ekspresi boolean mode: setiap term dicari sebagai awalan, dan
+// untuk term yang cukup panjang juga tiga huruf pertamanya agar salah ketik di bagian
+// akhir kata tetap mendapat kandidat
+func fullTextExpr(terms []string) string {
+	var parts []string
+	for _, term := range terms {
+		parts = append(parts, term+"*")
+		if typoAllowance(term) > 0 {
+			parts = append(parts, runePrefix(term, 3)+"*")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// MemorySearchIndex adalah index pencarian di memori proses. Cocok untuk pengujian dan
+// development tanpa index FULLTEXT; isinya diganti lewat Replace atau RunRefresher.
+type MemorySearchIndex struct {
+	mu       sync.RWMutex
+	products []models.Product
+}
+
+func NewMemorySearchIndex(products []models.Product) *MemorySearchIndex {
+	index := &MemorySearchIndex{}
+	index.Replace(products)
+	return index
+}
+
+// Replace mengganti seluruh isi index
+func (i *MemorySearchIndex) Replace(products []models.Product) {
+	sorted := append([]models.Product(nil), products...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].ID < sorted[b].ID })
+	i.mu.Lock()
+	i.products = sorted
+	i.mu.Unlock()
+}
+
+func (i *MemorySearchIndex) Candidates(ctx context.Context, terms []string, limit int) ([]models.Product, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var candidates []models.Product
+	for _, product := range i.products {
+		if len(candidates) == limit {
+			break
+		}
+		if scoreProduct(product, terms) > 0 {
+			candidates = append(candidates, product)
+		}
+	}
+	return candidates, nil
+}
+
+// RunRefresher memuat ulang index dari database setiap interval sampai ctx selesai.
+// Index dimuat sekali saat mulai; interval <= 0 berarti tidak dimuat ulang.
+func (i *MemorySearchIndex) RunRefresher(ctx context.Context, interval time.Duration, productRepo *repository.ProductRepository) {
+	refresh := func() {
+		products, err := productRepo.FindSearchable()
+		if err != nil {
+			log.Printf("search index: %v", err)
+			return
+		}
+		i.Replace(products)
+	}
+	refresh()
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+)
+
+func TestMatchTerm(t *testing.T) {
+	tests := []struct {
+		term, token string
+		want        float64
+	}{
+		{"kopi", "kopi", 3},
+		{"kop", "kopi", 2},
+		{"kopu", "kopi", 1},
+		{"kpoi", "kopi", 1},
+		// salah ketik terhadap awalan kata yang lebih panjang
+		{"kopu", "kopiah", 1},
+		{"keybaord", "keyboards", 1},
+		{"kopuu", "kopi", 0},
+		// kata pendek harus tepat
+		{"kpi", "kop", 0},
+		// huruf pertama berbeda tidak dianggap salah ketik
+		{"jopi", "kopi", 0},
+		{"teh", "kopi", 0},
+	}
+	for _, tt := range tests {
+		if got := matchTerm(tt.term, tt.token); got != tt.want {
+			t.Errorf("matchTerm(%q, %q) = %v, want %v", tt.term, tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kopi", "kopi", 0},
+		{"kitten", "sitting", 3},
+		{"ab", "ba", 1},
+		{"kpoi", "kopi", 1},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightTerms(t *testing.T) {
+	tests := []struct {
+		text  string
+		terms []string
+		want  string
+	}{
+		{"Kopi Arabica", []string{"kopi"}, "<mark>Kopi</mark> Arabica"},
+		{"Kopi Arabica", []string{"ara"}, "Kopi <mark>Arabica</mark>"},
+		{"Kopi Arabica", []string{"kopu", "arabika"}, "<mark>Kopi</mark> <mark>Arabica</mark>"},
+		{"Kopi & <Teh>", []string{"teh"}, "Kopi &amp; &lt;<mark>Teh</mark>&gt;"},
+		{"Teh Hijau", []string{"kopi"}, "Teh Hijau"},
+	}
+	for _, tt := range tests {
+		if got := highlightTerms(tt.text, tt.terms); got != tt.want {
+			t.Errorf("highlightTerms(%q, %v) = %q, want %q", tt.text, tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestSearchServiceMemoryIndex(t *testing.T) {
+	sku := "AREN-500"
+	index := NewMemorySearchIndex([]models.Product{
+		{ID: 1, Name: "Kopi Arabica", Stock: 0},
+		{ID: 2, Name: "Kopi Robusta", Stock: 5},
+		{ID: 3, Name: "Teh Hijau", Stock: 10, Tags: []models.Tag{{Name: "kopi"}}},
+		{ID: 4, Name: "Kopiah Putih", Stock: 3},
+		{ID: 5, Name: "Kopi Luwak", Stock: 5},
+		{ID: 6, Name: "Gula Aren", Stock: 8, SKU: &sku},
+	})
+	search := NewSearchService(index)
+
+	tests := []struct {
+		name      string
+		q         string
+		wantIDs   []uint
+		highlight string
+	}{
+		// skor sama diurutkan: ada stok dulu, stok terbanyak, lalu id
+		{"exact then prefix then tag", "kopi", []uint{2, 5, 1, 4, 3}, "<mark>Kopi</mark> Robusta"},
+		{"typo tolerant with all-terms bonus", "kopu robusta", []uint{2, 5, 4, 1, 3}, "<mark>Kopi</mark> <mark>Robusta</mark>"},
+		{"sku match", "aren-500", []uint{6}, "Gula <mark>Aren</mark>"},
+		{"no match", "sepatu", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, meta, err := search.Search(context.Background(), dto.ProductSearchQuery{Q: tt.q})
+			if err != nil {
+				t.Fatalf("Search(%q): %v", tt.q, err)
+			}
+			var ids []uint
+			for _, hit := range hits {
+				ids = append(ids, hit.Product.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Fatalf("Search(%q) ids = %v, want %v", tt.q, ids, tt.wantIDs)
+			}
+			if meta.Total != int64(len(tt.wantIDs)) {
+				t.Errorf("Search(%q) total = %d, want %d", tt.q, meta.Total, len(tt.wantIDs))
+			}
+			if len(hits) > 0 && hits[0].Highlight != tt.highlight {
+				t.Errorf("Search(%q) highlight = %q, want %q", tt.q, hits[0].Highlight, tt.highlight)
+			}
+		})
+	}
+
+	if _, _, err := search.Search(context.Background(), dto.ProductSearchQuery{Q: "+-*"}); !errors.Is(err, ErrInvalidSearchQuery) {
+		t.Errorf("Search(operators only) error = %v, want ErrInvalidSearchQuery", err)
+	}
+}
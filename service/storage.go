@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wahyuutomoputra/order-management/config"
+)
+
+// ErrFileNotFound dikembalikan storage saat key tidak ditemukan
+var ErrFileNotFound = errors.New("file not found")
+
+// Storage menyimpan file gambar produk. Key adalah path relatif dengan pemisah "/"
+// yang unik per file, sehingga file tidak pernah ditimpa.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open membuka file beserta waktu terakhir diubah untuk dikirim ke client
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, time.Time, error)
+	Delete(ctx context.Context, key string) error
+	// URL membentuk URL publik file
+	URL(key string) string
+}
+
+// NewStorage memilih storage berdasarkan konfigurasi; nilai yang tidak dikenal memakai LocalStorage
+func NewStorage(cfg config.ImageConfig) Storage {
+	switch cfg.Storage {
+	default:
+		return &LocalStorage{Dir: cfg.LocalDir, BaseURL: cfg.PublicURL}
+	}
+}
+
+// LocalStorage menyimpan file di filesystem lokal di bawah Dir
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+// path mengubah key menjadi path file dan menolak key yang keluar dari Dir
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", ErrFileNotFound
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// tulis ke file sementara lalu rename agar file yang dibaca tidak pernah setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadSeekCloser, time.Time, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, time.Time{}, ErrFileNotFound
+		}
+		return nil, time.Time{}, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, time.Time{}, ErrFileNotFound
+	}
+	return file, info.ModTime(), nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return strings.TrimRight(s.BaseURL, "/") + "/" + key
+}
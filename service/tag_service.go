@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+type TagService struct {
+	repo *repository.TagRepository
+}
+
+func NewTagService(repo *repository.TagRepository) *TagService {
+	return &TagService{repo}
+}
+
+func (s *TagService) FindAll() ([]models.Tag, error) {
+	return s.repo.FindAll()
+}
+
+func (s *TagService) Create(name string) (*models.Tag, error) {
+	tag := models.Tag{Name: normalizeTagName(name)}
+	if err := s.checkName(tag.Name, 0); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(&tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (s *TagService) Update(id uint, name string) (*models.Tag, error) {
+	tag, err := s.findByID(id)
+	if err != nil {
+		return nil, err
+	}
+	tag.Name = normalizeTagName(name)
+	if err := s.checkName(tag.Name, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *TagService) Delete(id uint) error {
+	tag, err := s.findByID(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(tag)
+}
+
+func (s *TagService) findByID(id uint) (*models.Tag, error) {
+	tag, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *TagService) checkName(name string, exceptID uint) error {
+	existing, err := s.repo.FindByName(name)
+	if err == nil && existing.ID != exceptID {
+		return ErrTagExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// normalizeTagName menyeragamkan nama tag agar "Sale" dan " sale" dianggap sama
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTagNames menormalkan dan menghapus duplikat nama tag
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
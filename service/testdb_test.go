@@ -0,0 +1,118 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB membuka database untuk test service. Jika TEST_MYSQL_DSN diisi, test
+// memakai MySQL tersebut (row lock dan retry deadlock ikut teruji); selain itu memakai
+// SQLite di direktori sementara dengan transaksi IMMEDIATE sehingga penulis berjalan
+// bergantian. Data test selalu dibuat baru sehingga aman untuk database bersama.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	var (
+		db  *gorm.DB
+		err error
+	)
+	if dsn := os.Getenv("TEST_MYSQL_DSN"); dsn != "" {
+		db, err = gorm.Open(mysql.Open(dsn), cfg)
+	} else {
+		dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)&_txlock=immediate"
+		db, err = gorm.Open(sqlite.Open(dsn), cfg)
+	}
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Category{},
+		&models.Tag{},
+		&models.Product{},
+		&models.ProductVariant{},
+		&models.ProductImage{},
+		&models.Review{},
+		&models.Order{},
+		&models.OrderItem{},
+		&models.OrderStatusHistory{},
+		&models.CartItem{},
+		&models.InventoryMovement{},
+		&models.Warehouse{},
+		&models.StockLevel{},
+		&models.StockReservation{},
+		&models.LowStockEvent{},
+		&models.EmailOutbox{},
+		&models.IdempotencyKey{},
+	); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// requireMySQL melewati test yang menguji row lock atau retry deadlock. SQLite dengan
+// transaksi IMMEDIATE menjalankan semua transaksi bergantian sehingga test seperti itu
+// selalu lolos tanpa membuktikan apa pun.
+func requireMySQL(t *testing.T) {
+	t.Helper()
+	if os.Getenv("TEST_MYSQL_DSN") == "" {
+		t.Skip("set TEST_MYSQL_DSN to run concurrency tests against MySQL")
+	}
+}
+
+// warehouseSeq membuat kode gudang test unik walau dibuat pada nanodetik yang sama
+var warehouseSeq atomic.Int64
+
+// seedWarehouse membuat gudang aktif baru dengan kode unik per test
+func seedWarehouse(t *testing.T, db *gorm.DB, priority int) models.Warehouse {
+	t.Helper()
+	warehouse := models.Warehouse{Code: "T" + strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatInt(warehouseSeq.Add(1), 10), Name: t.Name(), Priority: priority, Active: true}
+	if err := db.Create(&warehouse).Error; err != nil {
+		t.Fatalf("create warehouse: %v", err)
+	}
+	return warehouse
+}
+
+// seedProduct membuat produk tanpa varian lalu mengisi stoknya lewat ledger inventori
+func seedProduct(t *testing.T, db *gorm.DB, warehouse models.Warehouse, stock int) models.Product {
+	t.Helper()
+	product := models.Product{Name: "Test " + t.Name(), Price: models.NewMoney(1000)}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	restock(t, db, product.ID, warehouse.ID, stock)
+	return product
+}
+
+// restock menambah stok produk tanpa varian di satu gudang lewat ledger inventori
+func restock(t *testing.T, db *gorm.DB, productID, warehouseID uint, quantity int) {
+	t.Helper()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := repository.NewInventoryRepository(tx).Apply(&models.InventoryMovement{
+			ProductID:   productID,
+			WarehouseID: warehouseID,
+			Delta:       quantity,
+			Reason:      models.MovementRestock,
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("restock product: %v", err)
+	}
+}
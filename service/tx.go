@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	// kode error MySQL yang aman untuk diulang
+	mysqlErrLockDeadlock    = 1213
+	mysqlErrLockWaitTimeout = 1205
+
+	txMaxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// runInTx menjalankan fn di dalam transaksi dan mengulang seluruh transaksi
+// jika database membatalkannya karena deadlock atau lock wait timeout.
+func runInTx(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	var err error
+	for attempt := 1; attempt <= txMaxAttempts; attempt++ {
+		err = db.Transaction(fn)
+		if err == nil || !isRetryableTxError(err) {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * txRetryDelay)
+	}
+	return err
+}
+
+func isRetryableTxError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrLockDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/wahyuutomoputra/order-management/dto"
+	"github.com/wahyuutomoputra/order-management/models"
+	"github.com/wahyuutomoputra/order-management/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWarehouseNotFound  = errors.New("warehouse not found")
+	ErrWarehouseCodeTaken = errors.New("warehouse code already exists")
+	ErrNoActiveWarehouse  = repository.ErrNoActiveWarehouse
+)
+
+type WarehouseService struct {
+	repo *repository.WarehouseRepository
+}
+
+func NewWarehouseService(repo *repository.WarehouseRepository) *WarehouseService {
+	return &WarehouseService{repo}
+}
+
+func (s *WarehouseService) FindAll() ([]models.Warehouse, error) {
+	return s.repo.FindAll()
+}
+
+func (s *WarehouseService) Create(req dto.WarehouseRequest) (*models.Warehouse, error) {
+	warehouse := models.Warehouse{
+		Code:     normalizeWarehouseCode(req.Code),
+		Name:     strings.TrimSpace(req.Name),
+		Priority: req.Priority,
+		Active:   req.Active == nil || *req.Active,
+	}
+	if err := s.checkCode(warehouse.Code, 0); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(&warehouse); err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// Update mengubah gudang. Active nil berarti status aktif tidak diubah.
+func (s *WarehouseService) Update(id uint, req dto.WarehouseRequest) (*models.Warehouse, error) {
+	warehouse, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWarehouseNotFound
+		}
+		return nil, err
+	}
+	code := normalizeWarehouseCode(req.Code)
+	if err := s.checkCode(code, warehouse.ID); err != nil {
+		return nil, err
+	}
+	warehouse.Code = code
+	warehouse.Name = strings.TrimSpace(req.Name)
+	warehouse.Priority = req.Priority
+	if req.Active != nil {
+		warehouse.Active = *req.Active
+	}
+	if err := s.repo.Update(warehouse); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (s *WarehouseService) checkCode(code string, exceptID uint) error {
+	existing, err := s.repo.FindByCode(code)
+	if err == nil && existing.ID != exceptID {
+		return ErrWarehouseCodeTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func normalizeWarehouseCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -5,18 +5,25 @@ import "github.com/gin-gonic/gin"
 type SuccessResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
 	Message string      `json:"message,omitempty"`
 }
 
 type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
+	Success bool        `json:"success"`
+	Error   string      `json:"error"`
+	Data    interface{} `json:"data,omitempty"`
 }
 
 func JSONSuccess(c *gin.Context, data interface{}, message string) {
 	c.JSON(200, SuccessResponse{Success: true, Data: data, Message: message})
 }
 
+// JSONSuccessWithMeta mengirim data beserta metadata tambahan, misal informasi pagination
+func JSONSuccessWithMeta(c *gin.Context, data interface{}, meta interface{}, message string) {
+	c.JSON(200, SuccessResponse{Success: true, Data: data, Meta: meta, Message: message})
+}
+
 func JSONCreated(c *gin.Context, data interface{}, message string) {
 	c.JSON(201, SuccessResponse{Success: true, Data: data, Message: message})
 }
@@ -24,3 +31,8 @@ func JSONCreated(c *gin.Context, data interface{}, message string) {
 func JSONError(c *gin.Context, code int, err string) {
 	c.JSON(code, ErrorResponse{Success: false, Error: err})
 }
+
+// JSONErrorWithData mengirim error beserta data, misal representasi terkini saat terjadi konflik
+func JSONErrorWithData(c *gin.Context, code int, err string, data interface{}) {
+	c.JSON(code, ErrorResponse{Success: false, Error: err, Data: data})
+}
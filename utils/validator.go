@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/wahyuutomoputra/order-management/models"
+)
+
+// NewValidator membuat validator yang memvalidasi models.Money berdasarkan nominal
+// minor unit-nya, sehingga tag seperti `validate:"required,gt=0"` tetap bisa dipakai
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if m, ok := field.Interface().(models.Money); ok {
+			return m.Amount
+		}
+		return nil
+	}, models.Money{})
+	return v
+}